@@ -0,0 +1,60 @@
+// Command eventschema writes the JSON Schema of every wallet event payload.
+// It refuses to overwrite a schema with one its consumers couldn't read.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/schema"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+)
+
+func main() {
+	dir := flag.String("dir", "pkg/events/wallet/schemas", "directory the schemas are written to")
+	flag.Parse()
+
+	if err := run(*dir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, payload := range wallet.Payloads {
+		current := schema.ForPayload(payload)
+		path := filepath.Join(dir, schema.FileName(payload))
+
+		content, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return err
+		default:
+			previous, err := schema.Unmarshal(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if err = schema.Compatible(previous, current); err != nil {
+				return fmt.Errorf("%s: incompatible change, publish a new schema version instead:\n%w", path, err)
+			}
+		}
+
+		content, err = schema.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+		log.Println("wrote", path)
+	}
+	return nil
+}
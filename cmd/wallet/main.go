@@ -3,11 +3,25 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/config"
 	"github.com/alexandrebrunodias/wallet-core/internal/database/postgres"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/fx"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_account"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_customer"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_transaction"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_account"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_account_statement"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_customer"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_transaction"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/reconcile_ledger"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/reverse_transaction"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/sweep_idempotency_keys"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_customer"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_overdraft_limit"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_transfer_limits"
 	"github.com/alexandrebrunodias/wallet-core/internal/web"
 	"github.com/alexandrebrunodias/wallet-core/pkg/events"
 	"github.com/alexandrebrunodias/wallet-core/pkg/events/kafka"
@@ -16,68 +30,241 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	_ "github.com/lib/pq"
+	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
-const TransactionsTopic = "wallet.transactions"
-
 func main() {
-	// TODO IMPROVE CONFIG
-	pgHost := os.Getenv("POSTGRES_HOST")
-
-	db, err := sql.Open(
-		"postgres",
-		fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			"postgres",
-			"senha",
-			pgHost,
-			"5432",
-			"wallet",
-		),
-	)
+	if err := run(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// run serves requests until SIGINT or SIGTERM, then shuts every component
+// down within the configured deadline. It returns every error met on the way.
+func run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	log.Printf("starting with config:\n%s", cfg)
+
+	fxRateProvider, err := newFXRateProvider(cfg.FX)
+	if err != nil {
+		return err
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	db, err := sql.Open("postgres", cfg.Database.DataSourceName())
 	if err != nil {
-		panic(err)
+		return err
 	}
-	defer db.Close()
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	customerGateway := postgres.NewCustomerPgGateway(db)
 	accountGateway := postgres.NewAccountPgGateway(db)
+	transactionGateway := postgres.NewTransactionPgGateway(db)
 
 	ctx := context.Background()
 	unitOfWork := uow.NewUnitOfWork(ctx, db)
+	unitOfWork.Add("CustomerGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewCustomerPgGateway(tx)
+	})
 	unitOfWork.Add("AccountGateway", func(tx *sql.Tx) interface{} {
-		return postgres.NewAccountPgGateway(db)
+		return postgres.NewAccountPgGateway(tx)
 	})
 	unitOfWork.Add("TransactionGateway", func(tx *sql.Tx) interface{} {
-		return postgres.NewTransactionPgGateway(db)
+		return postgres.NewTransactionPgGateway(tx)
+	})
+	unitOfWork.Add("LedgerGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewLedgerPgGateway(tx)
+	})
+	unitOfWork.Add("IdempotencyGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewIdempotencyPgGateway(tx)
 	})
+	unitOfWork.Add("OutboxGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewOutboxPgGateway(tx)
+	})
+	unitOfWork.Add("AuditGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewAuditPgGateway(tx)
+	})
+
+	configMap := &ckafka.ConfigMap{}
+	for key, value := range cfg.Kafka.ProducerProperties() {
+		if err = configMap.SetKey(key, value); err != nil {
+			return errors.Join(err, db.Close())
+		}
+	}
 
-	kafkaHost := os.Getenv("KAFKA_HOST")
-	kafkaPort := os.Getenv("KAFKA_PORT")
-	configMap := &ckafka.ConfigMap{
-		"bootstrap.servers": fmt.Sprintf("%s:%s", kafkaHost, kafkaPort),
+	kafkaProducer := kafka.NewKafkaProducer(configMap, cfg.Topics.Transactions, nil)
+	kafkaProducer.Topics = map[string]string{
+		create_transaction.AccountBalanceUpdated:   cfg.Topics.Balances,
+		update_customer.CustomerUpdated:            cfg.Topics.Customers,
+		update_overdraft_limit.AccountLimitChanged: cfg.Topics.Accounts,
+	}
+	// Outbox rows stored before events carried a key get the same one back.
+	kafkaProducer.KeyExtractors = events.KeyExtractors{
+		create_transaction.TransactionCreated:   events.ContentField("from_account_id"),
+		reverse_transaction.TransactionReversed: events.ContentField("to_account_id"),
+	}
+	kafkaProducer.Encoder, err = events.NewEncoder(events.Encoding(cfg.Events.Encoding), cfg.Events.Source, events.KeyExtractors{
+		create_transaction.TransactionCreated:      events.ContentField("id"),
+		reverse_transaction.TransactionReversed:    events.ContentField("id"),
+		create_transaction.AccountBalanceUpdated:   events.ContentField("from_account_id"),
+		update_customer.CustomerUpdated:            events.ContentField("id"),
+		update_overdraft_limit.AccountLimitChanged: events.ContentField("account_id"),
+	})
+	if err != nil {
+		return errors.Join(err, db.Close())
 	}
+	outboxRelay := events.NewOutboxRelay(postgres.NewOutboxPgGateway(db), kafkaProducer)
+	outboxRelay.BatchSize = cfg.Outbox.BatchSize
+	outboxRelay.PollInterval = cfg.Outbox.PollInterval
+	outboxRelay.Backoff = events.ExponentialBackoff(cfg.Outbox.BackoffBase, cfg.Outbox.BackoffMax)
+
+	relayContext, stopRelay := context.WithCancel(ctx)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outboxRelay.Run(relayContext)
+	}()
 
-	kafkaProducer := kafka.NewKafkaProducer(configMap, TransactionsTopic, nil)
-	transactionEventPublisher := events.NewEventPublisher(kafkaProducer)
+	sweepIdempotencyKeysUseCase := sweep_idempotency_keys.NewSweepIdempotencyKeysUseCase(postgres.NewIdempotencyPgGateway(db))
+	sweepIdempotencyKeysUseCase.Interval = cfg.Transactions.IdempotencySweepInterval
+
+	sweepContext, stopSweep := context.WithCancel(ctx)
+	sweepDone := make(chan struct{})
+	go func() {
+		defer close(sweepDone)
+		sweepIdempotencyKeysUseCase.Run(sweepContext)
+	}()
 
 	createCustomerUseCase := create_customer.NewCreateCustomerUseCase(customerGateway)
 	createAccountUseCase := create_account.NewCreateAccountUseCase(accountGateway, customerGateway)
-	createTransactionUseCase := create_transaction.NewCreateTransactionUseCase(unitOfWork, transactionEventPublisher)
+	createTransactionUseCase := create_transaction.NewCreateTransactionUseCase(unitOfWork)
+	createTransactionUseCase.Locking = create_transaction.LockingStrategy(cfg.Transactions.Locking)
+	createTransactionUseCase.MaxConflictRetries = cfg.Transactions.MaxConflictRetries
+	createTransactionUseCase.IdempotencyKeyTTL = cfg.Transactions.IdempotencyKeyTTL
+	createTransactionUseCase.FXRateProvider = fxRateProvider
+	createTransactionUseCase.Rounding = entity.RoundingMode(cfg.FX.Rounding)
+	reverseTransactionUseCase := reverse_transaction.NewReverseTransactionUseCase(unitOfWork)
+	getCustomerUseCase := get_customer.NewGetCustomerUseCase(customerGateway)
+	updateCustomerUseCase := update_customer.NewUpdateCustomerUseCase(unitOfWork)
+	getAccountUseCase := get_account.NewGetAccountUseCase(accountGateway, customerGateway)
+	getTransactionUseCase := get_transaction.NewGetTransactionUseCase(accountGateway, transactionGateway)
+	getAccountStatementUseCase := get_account_statement.NewGetAccountStatementUseCase(accountGateway, transactionGateway)
+	updateOverdraftLimitUseCase := update_overdraft_limit.NewUpdateOverdraftLimitUseCase(unitOfWork)
+	updateTransferLimitsUseCase := update_transfer_limits.NewUpdateTransferLimitsUseCase(unitOfWork)
+	reconcileLedgerUseCase := reconcile_ledger.NewReconcileLedgerUseCase(postgres.NewLedgerPgGateway(db))
 
-	customerHandler := web.NewCustomerHandler(*createCustomerUseCase)
-	accountHandler := web.NewAccountHandler(*createAccountUseCase)
-	transactionHandler := web.NewTransactionHandler(*createTransactionUseCase)
+	customerHandler := web.NewCustomerHandler(*createCustomerUseCase, *getCustomerUseCase, *updateCustomerUseCase)
+	accountHandler := web.NewAccountHandler(
+		*createAccountUseCase,
+		*getAccountUseCase,
+		*getAccountStatementUseCase,
+		*updateOverdraftLimitUseCase,
+		*updateTransferLimitsUseCase,
+	)
+	ledgerHandler := web.NewLedgerHandler(*reconcileLedgerUseCase)
+	transactionHandler := web.NewTransactionHandler(
+		*createTransactionUseCase,
+		*reverseTransactionUseCase,
+		*getTransactionUseCase,
+	)
 
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	router.Post("/customers", customerHandler.CreateCustomer)
+	router.Get("/customers", customerHandler.FindCustomer)
+	router.Get("/customers/{id}", customerHandler.GetCustomer)
+	router.Patch("/customers/{id}", customerHandler.UpdateCustomer)
 	router.Post("/accounts", accountHandler.CreateAccount)
+	router.Get("/accounts/{id}", accountHandler.GetAccount)
+	router.Get("/accounts/{id}/transactions", accountHandler.GetAccountStatement)
 	router.Post("/transactions", transactionHandler.CreateTransaction)
+	router.Get("/transactions/{id}", transactionHandler.GetTransaction)
+	router.Post("/transactions/{id}/reversal", transactionHandler.ReverseTransaction)
+	if cfg.Admin.Token != "" {
+		router.Route("/admin", web.AdminRoutes(cfg.Admin.Token, accountHandler, ledgerHandler))
+	} else {
+		log.Println("admin routes disabled: 'admin.token' is not set")
+	}
+
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           router,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Server is running on", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
 
-	webServerPort := ":8000"
-	fmt.Println("Server is running on port", webServerPort)
-	panic(http.ListenAndServe(webServerPort, router))
+	var errs []error
+	select {
+	case err = <-serverErr:
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	case <-signals.Done():
+		log.Println("shutting down")
+	}
+	// A second signal kills the process right away.
+	stopSignals()
+
+	shutdownContext, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Shutdown stops accepting connections and waits for in-flight requests,
+	// so transfers being executed still commit.
+	if err = server.Shutdown(shutdownContext); err != nil {
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+
+	// The relay finishes the batch it is sending before returning.
+	stopRelay()
+	select {
+	case <-relayDone:
+	case <-shutdownContext.Done():
+		errs = append(errs, fmt.Errorf("outbox relay shutdown: %w", shutdownContext.Err()))
+	}
+
+	stopSweep()
+	select {
+	case <-sweepDone:
+	case <-shutdownContext.Done():
+		errs = append(errs, fmt.Errorf("idempotency key sweep shutdown: %w", shutdownContext.Err()))
+	}
+
+	if err = kafkaProducer.Close(shutdownContext); err != nil {
+		errs = append(errs, fmt.Errorf("kafka producer shutdown: %w", err))
+	}
+
+	if err = db.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("database shutdown: %w", err))
+	}
+
+	if len(errs) == 0 {
+		log.Println("shut down cleanly")
+	}
+	return errors.Join(errs...)
+}
+
+// newFXRateProvider reads the configured rates file, or the rates listed in
+// the config when there is none.
+func newFXRateProvider(cfg config.FXConfig) (*fx.StaticRateProvider, error) {
+	if cfg.RatesFile != "" {
+		return fx.NewFileRateProvider(cfg.RatesFile)
+	}
+	return fx.NewStaticRateProvider(cfg.Rates)
 }
@@ -0,0 +1,413 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"gopkg.in/yaml.v3"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFileEnv names the environment variable holding the optional YAML
+// config file.
+const ConfigFileEnv = "WALLET_CONFIG_FILE"
+
+const redacted = "*****"
+
+// Config holds every setting of cmd/wallet. Values come from Default, then
+// the YAML file, then environment variables, the last one winning.
+type Config struct {
+	Database     DatabaseConfig     `yaml:"database"`
+	Kafka        KafkaConfig        `yaml:"kafka"`
+	Topics       TopicsConfig       `yaml:"topics"`
+	Events       EventsConfig       `yaml:"events"`
+	HTTP         HTTPConfig         `yaml:"http"`
+	Transactions TransactionsConfig `yaml:"transactions"`
+	FX           FXConfig           `yaml:"fx"`
+	Outbox       OutboxConfig       `yaml:"outbox"`
+	Admin        AdminConfig        `yaml:"admin"`
+	// ShutdownTimeout bounds draining requests, relaying and flushing events
+	// and closing the database once a termination signal arrives.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type DatabaseConfig struct {
+	// DSN takes precedence over the individual connection settings.
+	DSN             string        `yaml:"dsn" env:"POSTGRES_DSN"`
+	Host            string        `yaml:"host" env:"POSTGRES_HOST"`
+	Port            int           `yaml:"port" env:"POSTGRES_PORT"`
+	User            string        `yaml:"user" env:"POSTGRES_USER"`
+	Password        string        `yaml:"password" env:"POSTGRES_PASSWORD"`
+	Name            string        `yaml:"name" env:"POSTGRES_DB"`
+	SSLMode         string        `yaml:"ssl_mode" env:"POSTGRES_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"POSTGRES_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"POSTGRES_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"POSTGRES_CONN_MAX_LIFETIME"`
+}
+
+type KafkaConfig struct {
+	BootstrapServers string `yaml:"bootstrap_servers" env:"KAFKA_BOOTSTRAP_SERVERS"`
+	ClientID         string `yaml:"client_id" env:"KAFKA_CLIENT_ID"`
+	// EnableIdempotence keeps broker-side retries from duplicating or
+	// reordering events. It requires Acks "all" and at most 5 in-flight
+	// requests.
+	EnableIdempotence bool          `yaml:"enable_idempotence" env:"KAFKA_ENABLE_IDEMPOTENCE"`
+	Acks              string        `yaml:"acks" env:"KAFKA_ACKS"`
+	MaxInFlight       int           `yaml:"max_in_flight" env:"KAFKA_MAX_IN_FLIGHT"`
+	DeliveryTimeout   time.Duration `yaml:"delivery_timeout" env:"KAFKA_DELIVERY_TIMEOUT"`
+	// Properties are passed to librdkafka as is, overriding the settings
+	// above.
+	Properties map[string]string `yaml:"properties"`
+}
+
+type TopicsConfig struct {
+	Transactions string `yaml:"transactions" env:"TOPIC_TRANSACTIONS"`
+	Balances     string `yaml:"balances" env:"TOPIC_BALANCES"`
+	Customers    string `yaml:"customers" env:"TOPIC_CUSTOMERS"`
+	Accounts     string `yaml:"accounts" env:"TOPIC_ACCOUNTS"`
+}
+
+type EventsConfig struct {
+	// Encoding is "native", "cloudevents-structured" or "cloudevents-binary".
+	Encoding string `yaml:"encoding" env:"EVENTS_ENCODING"`
+	// Source is the CloudEvents source attribute.
+	Source string `yaml:"source" env:"EVENTS_SOURCE"`
+}
+
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"HTTP_ADDR"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+}
+
+type TransactionsConfig struct {
+	// Locking is either "pessimistic" or "optimistic".
+	Locking                  string        `yaml:"locking" env:"TRANSACTIONS_LOCKING"`
+	MaxConflictRetries       int           `yaml:"max_conflict_retries" env:"TRANSACTIONS_MAX_CONFLICT_RETRIES"`
+	IdempotencyKeyTTL        time.Duration `yaml:"idempotency_key_ttl" env:"TRANSACTIONS_IDEMPOTENCY_KEY_TTL"`
+	IdempotencySweepInterval time.Duration `yaml:"idempotency_sweep_interval" env:"TRANSACTIONS_IDEMPOTENCY_SWEEP_INTERVAL"`
+}
+
+type FXConfig struct {
+	// RatesFile is a YAML or JSON file mapping pairs written "BRL/USD" to
+	// rates. It replaces Rates when set.
+	RatesFile string            `yaml:"rates_file" env:"FX_RATES_FILE"`
+	Rates     map[string]string `yaml:"rates"`
+	// Rounding is "half_even", "half_up", "down" or "up".
+	Rounding string `yaml:"rounding" env:"FX_ROUNDING"`
+}
+
+type OutboxConfig struct {
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BackoffBase  time.Duration `yaml:"backoff_base" env:"OUTBOX_BACKOFF_BASE"`
+	BackoffMax   time.Duration `yaml:"backoff_max" env:"OUTBOX_BACKOFF_MAX"`
+}
+
+type AdminConfig struct {
+	// Token guards the /admin routes, sent as "Authorization: Bearer <token>".
+	// They aren't served at all while it is blank.
+	Token string `yaml:"token" env:"ADMIN_TOKEN"`
+}
+
+// minAdminTokenLength keeps admin tokens out of reach of guessing.
+const minAdminTokenLength = 16
+
+func Default() *Config {
+	return &Config{
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "wallet",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		Kafka: KafkaConfig{
+			BootstrapServers:  "localhost:9092",
+			ClientID:          "wallet-core",
+			EnableIdempotence: true,
+			Acks:              "all",
+			MaxInFlight:       5,
+			DeliveryTimeout:   30 * time.Second,
+		},
+		Topics: TopicsConfig{
+			Transactions: "wallet.transactions",
+			Balances:     "wallet.balances",
+			Customers:    "wallet.customers",
+			Accounts:     "wallet.accounts",
+		},
+		Events: EventsConfig{
+			Encoding: string(events.NativeEncoding),
+			Source:   "/wallet-core",
+		},
+		HTTP: HTTPConfig{
+			Addr:              ":8000",
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			IdleTimeout:       time.Minute,
+		},
+		Transactions: TransactionsConfig{
+			Locking:                  "pessimistic",
+			MaxConflictRetries:       3,
+			IdempotencyKeyTTL:        24 * time.Hour,
+			IdempotencySweepInterval: time.Hour,
+		},
+		FX: FXConfig{
+			Rounding: string(entity.DefaultRoundingMode),
+		},
+		Outbox: OutboxConfig{
+			BatchSize:    100,
+			PollInterval: time.Second,
+			BackoffBase:  time.Second,
+			BackoffMax:   5 * time.Minute,
+		},
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Load reads the file named by WALLET_CONFIG_FILE, if any, and the
+// environment on top of the defaults, then validates the result.
+func Load() (*Config, error) {
+	return load(os.Getenv(ConfigFileEnv), os.LookupEnv)
+}
+
+func load(path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if err = yaml.Unmarshal(content, config); err != nil {
+			return nil, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(config).Elem(), lookupEnv); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	// KAFKA_HOST and KAFKA_PORT predate KAFKA_BOOTSTRAP_SERVERS.
+	if _, set := lookupEnv("KAFKA_BOOTSTRAP_SERVERS"); !set {
+		host, hostSet := lookupEnv("KAFKA_HOST")
+		port, portSet := lookupEnv("KAFKA_PORT")
+		if hostSet && portSet {
+			config.Kafka.BootstrapServers = net.JoinHostPort(host, port)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return config, nil
+}
+
+// applyEnv overrides every field tagged with `env` whose variable is set.
+func applyEnv(value reflect.Value, lookupEnv func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, lookupEnv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := value.Type().Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw, set := lookupEnv(name)
+		if !set {
+			continue
+		}
+
+		var err error
+		switch field.Interface().(type) {
+		case string:
+			field.SetString(raw)
+		case int:
+			var parsed int
+			parsed, err = strconv.Atoi(raw)
+			field.SetInt(int64(parsed))
+		case bool:
+			var parsed bool
+			parsed, err = strconv.ParseBool(raw)
+			field.SetBool(parsed)
+		case time.Duration:
+			var parsed time.Duration
+			parsed, err = time.ParseDuration(raw)
+			field.SetInt(int64(parsed))
+		default:
+			err = fmt.Errorf("unsupported type %s", field.Type())
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if c.Database.DSN == "" {
+		check(c.Database.Host != "", "'database.host' should not be blank")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "'database.port' %d is out of range", c.Database.Port)
+		check(c.Database.User != "", "'database.user' should not be blank")
+		check(c.Database.Name != "", "'database.name' should not be blank")
+	}
+	check(c.Database.MaxOpenConns >= 0, "'database.max_open_conns' should not be negative")
+	check(c.Database.MaxIdleConns >= 0, "'database.max_idle_conns' should not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"'database.max_idle_conns' should not exceed 'database.max_open_conns'")
+	check(c.Database.ConnMaxLifetime >= 0, "'database.conn_max_lifetime' should not be negative")
+
+	check(c.Kafka.BootstrapServers != "", "'kafka.bootstrap_servers' should not be blank")
+	check(c.Kafka.Acks == "all" || c.Kafka.Acks == "-1" || c.Kafka.Acks == "0" || c.Kafka.Acks == "1",
+		"'kafka.acks' must be \"all\", \"-1\", \"0\" or \"1\", got %q", c.Kafka.Acks)
+	check(c.Kafka.MaxInFlight > 0, "'kafka.max_in_flight' should be positive")
+	check(c.Kafka.DeliveryTimeout > 0, "'kafka.delivery_timeout' should be positive")
+	if c.Kafka.EnableIdempotence {
+		check(c.Kafka.Acks == "all" || c.Kafka.Acks == "-1", "'kafka.enable_idempotence' requires 'kafka.acks' \"all\"")
+		check(c.Kafka.MaxInFlight <= 5, "'kafka.enable_idempotence' requires 'kafka.max_in_flight' of at most 5")
+	}
+	check(c.Topics.Transactions != "", "'topics.transactions' should not be blank")
+	check(c.Topics.Balances != "", "'topics.balances' should not be blank")
+	check(c.Topics.Customers != "", "'topics.customers' should not be blank")
+	check(c.Topics.Accounts != "", "'topics.accounts' should not be blank")
+
+	switch events.Encoding(c.Events.Encoding) {
+	case events.NativeEncoding:
+	case events.CloudEventsStructured, events.CloudEventsBinary:
+		check(c.Events.Source != "", "'events.source' should not be blank")
+	default:
+		check(false, "'events.encoding' must be %q, %q or %q, got %q",
+			events.NativeEncoding, events.CloudEventsStructured, events.CloudEventsBinary, c.Events.Encoding)
+	}
+
+	check(c.HTTP.Addr != "", "'http.addr' should not be blank")
+	check(c.HTTP.ReadTimeout > 0, "'http.read_timeout' should be positive")
+	check(c.HTTP.ReadHeaderTimeout > 0, "'http.read_header_timeout' should be positive")
+	check(c.HTTP.WriteTimeout > 0, "'http.write_timeout' should be positive")
+	check(c.HTTP.IdleTimeout > 0, "'http.idle_timeout' should be positive")
+
+	check(c.Transactions.Locking == "pessimistic" || c.Transactions.Locking == "optimistic",
+		"'transactions.locking' must be \"pessimistic\" or \"optimistic\", got %q", c.Transactions.Locking)
+	check(c.Transactions.MaxConflictRetries >= 0, "'transactions.max_conflict_retries' should not be negative")
+	check(c.Transactions.IdempotencyKeyTTL > 0, "'transactions.idempotency_key_ttl' should be positive")
+	check(c.Transactions.IdempotencySweepInterval > 0, "'transactions.idempotency_sweep_interval' should be positive")
+
+	switch entity.RoundingMode(c.FX.Rounding) {
+	case entity.RoundHalfEven, entity.RoundHalfUp, entity.RoundDown, entity.RoundUp:
+	default:
+		check(false, "'fx.rounding' must be %q, %q, %q or %q, got %q",
+			entity.RoundHalfEven, entity.RoundHalfUp, entity.RoundDown, entity.RoundUp, c.FX.Rounding)
+	}
+
+	check(c.Outbox.BatchSize > 0, "'outbox.batch_size' should be positive")
+	check(c.Outbox.PollInterval > 0, "'outbox.poll_interval' should be positive")
+	check(c.Outbox.BackoffBase > 0, "'outbox.backoff_base' should be positive")
+	check(c.Outbox.BackoffMax >= c.Outbox.BackoffBase, "'outbox.backoff_max' should not be below 'outbox.backoff_base'")
+
+	check(c.Admin.Token == "" || len(c.Admin.Token) >= minAdminTokenLength,
+		"'admin.token' should be at least %d characters long", minAdminTokenLength)
+
+	check(c.ShutdownTimeout > 0, "'shutdown_timeout' should be positive")
+
+	return errors.Join(errs...)
+}
+
+// DataSourceName returns DSN when set, or a postgres URL built from the
+// individual settings.
+func (d DatabaseConfig) DataSourceName() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// ProducerProperties returns the librdkafka settings of the event producer.
+func (k KafkaConfig) ProducerProperties() map[string]string {
+	properties := map[string]string{
+		"bootstrap.servers":                     k.BootstrapServers,
+		"client.id":                             k.ClientID,
+		"enable.idempotence":                    strconv.FormatBool(k.EnableIdempotence),
+		"acks":                                  k.Acks,
+		"max.in.flight.requests.per.connection": strconv.Itoa(k.MaxInFlight),
+		"delivery.timeout.ms":                   strconv.FormatInt(k.DeliveryTimeout.Milliseconds(), 10),
+	}
+	for key, value := range k.Properties {
+		properties[key] = value
+	}
+	return properties
+}
+
+// Redacted returns a copy of the config safe to log.
+func (c *Config) Redacted() *Config {
+	copied := *c
+	if copied.Database.Password != "" {
+		copied.Database.Password = redacted
+	}
+	copied.Database.DSN = redactDSN(c.Database.DSN)
+	if copied.Admin.Token != "" {
+		copied.Admin.Token = redacted
+	}
+
+	copied.Kafka.Properties = make(map[string]string, len(c.Kafka.Properties))
+	for key, value := range c.Kafka.Properties {
+		if strings.Contains(key, "password") || strings.Contains(key, "secret") {
+			value = redacted
+		}
+		copied.Kafka.Properties[key] = value
+	}
+	return &copied
+}
+
+// redactDSN masks the password of URL DSNs and, not knowing where it sits,
+// the whole of keyword/value DSNs mentioning one.
+func redactDSN(dsn string) string {
+	parsed, err := url.Parse(dsn)
+	if err == nil && parsed.Scheme != "" {
+		return parsed.Redacted()
+	}
+	if strings.Contains(dsn, "password") {
+		return redacted
+	}
+	return dsn
+}
+
+// String dumps the redacted config as YAML.
+func (c *Config) String() string {
+	content, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(content)
+}
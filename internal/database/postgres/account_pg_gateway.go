@@ -3,21 +3,22 @@ package postgres
 import (
 	"database/sql"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
 type AccountPgGateway struct {
-	DB *sql.DB
+	DB Executor
 }
 
-func NewAccountPgGateway(db *sql.DB) *AccountPgGateway {
+func NewAccountPgGateway(db Executor) *AccountPgGateway {
 	return &AccountPgGateway{DB: db}
 }
 
 func (a AccountPgGateway) Create(account *entity.Account) error {
-	query := `INSERT INTO accounts (id, customer_id, balance, created_at, updated_at) 
-				VALUES ($1, $2, $3, $4, $5)`
+	query := `INSERT INTO accounts (id, customer_id, currency, balance, overdraft_limit, max_transfer_amount, max_daily_amount, max_daily_count, version, created_at, updated_at) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	stmt, err := a.DB.Prepare(query)
 	if err != nil {
@@ -28,7 +29,13 @@ func (a AccountPgGateway) Create(account *entity.Account) error {
 	_, err = stmt.Exec(
 		account.ID,
 		account.Customer.ID,
+		account.Currency,
 		account.Balance,
+		account.OverdraftLimit,
+		account.TransferLimits.MaxTransferAmount,
+		account.TransferLimits.MaxDailyAmount,
+		account.TransferLimits.MaxDailyCount,
+		account.Version,
 		account.CreatedAt,
 		account.UpdatedAt,
 	)
@@ -39,8 +46,11 @@ func (a AccountPgGateway) Create(account *entity.Account) error {
 	return nil
 }
 
-func (a AccountPgGateway) UpdateBalance(ID uuid.UUID, amount decimal.Decimal) error {
-	query := `UPDATE accounts SET balance = $1 WHERE id = $2`
+// UpdateBalance only succeeds when the stored version still matches the one
+// the balance was computed from, returning a *gateway.VersionConflictError
+// otherwise.
+func (a AccountPgGateway) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	query := `UPDATE accounts SET balance = $1, version = version + 1 WHERE id = $2 AND version = $3`
 
 	stmt, err := a.DB.Prepare(query)
 	if err != nil {
@@ -48,22 +58,103 @@ func (a AccountPgGateway) UpdateBalance(ID uuid.UUID, amount decimal.Decimal) er
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(amount, ID)
+	result, err := stmt.Exec(amount, ID, version)
 	if err != nil {
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &gateway.VersionConflictError{Entity: "account", ID: ID, Version: version}
+	}
+
+	return nil
+}
+
+// UpdateOverdraftLimit follows the same optimistic locking as UpdateBalance.
+func (a AccountPgGateway) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	query := `UPDATE accounts SET overdraft_limit = $1, version = version + 1 WHERE id = $2 AND version = $3`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(limit, ID, version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &gateway.VersionConflictError{Entity: "account", ID: ID, Version: version}
+	}
+
+	return nil
+}
+
+// UpdateTransferLimits replaces every transfer limit, clearing the nil ones,
+// and follows the same optimistic locking as UpdateBalance.
+func (a AccountPgGateway) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	query := `UPDATE accounts
+				SET max_transfer_amount = $1, max_daily_amount = $2, max_daily_count = $3, version = version + 1
+				WHERE id = $4 AND version = $5`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(limits.MaxTransferAmount, limits.MaxDailyAmount, limits.MaxDailyCount, ID, version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &gateway.VersionConflictError{Entity: "account", ID: ID, Version: version}
+	}
+
 	return nil
 }
 
 func (a AccountPgGateway) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	query := `SELECT id, customer_id, currency, balance, overdraft_limit, max_transfer_amount, max_daily_amount, max_daily_count,
+					version, created_at, updated_at
+			  	FROM accounts
+			  	WHERE id = $1`
+	return a.getAccount(query, ID)
+}
+
+// GetByIDForUpdate locks the account row until the surrounding transaction
+// ends, so concurrent transfers can't debit the same balance twice.
+func (a AccountPgGateway) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	query := `SELECT id, customer_id, currency, balance, overdraft_limit, max_transfer_amount, max_daily_amount, max_daily_count,
+					version, created_at, updated_at
+			  	FROM accounts
+			  	WHERE id = $1
+			  	FOR UPDATE`
+	return a.getAccount(query, ID)
+}
+
+func (a AccountPgGateway) getAccount(query string, ID uuid.UUID) (*entity.Account, error) {
 	var account entity.Account
 	var customer entity.Customer
+	var maxTransferAmount, maxDailyAmount decimal.NullDecimal
+	var maxDailyCount sql.NullInt64
 	account.Customer = &customer
 
-	query := `SELECT id, customer_id, balance, created_at, updated_at
-			  	FROM accounts
-			  	WHERE id = $1`
 	stmt, err := a.DB.Prepare(query)
 	if err != nil {
 		return nil, err
@@ -75,7 +166,13 @@ func (a AccountPgGateway) GetByID(ID uuid.UUID) (*entity.Account, error) {
 		Scan(
 			&account.ID,
 			&account.Customer.ID,
+			&account.Currency,
 			&account.Balance,
+			&account.OverdraftLimit,
+			&maxTransferAmount,
+			&maxDailyAmount,
+			&maxDailyCount,
+			&account.Version,
 			&account.CreatedAt,
 			&account.UpdatedAt,
 		)
@@ -83,5 +180,15 @@ func (a AccountPgGateway) GetByID(ID uuid.UUID) (*entity.Account, error) {
 		return nil, err
 	}
 
+	if maxTransferAmount.Valid {
+		account.TransferLimits.MaxTransferAmount = &maxTransferAmount.Decimal
+	}
+	if maxDailyAmount.Valid {
+		account.TransferLimits.MaxDailyAmount = &maxDailyAmount.Decimal
+	}
+	if maxDailyCount.Valid {
+		account.TransferLimits.MaxDailyCount = &maxDailyCount.Int64
+	}
+
 	return &account, err
 }
@@ -3,7 +3,9 @@ package postgres
 import (
 	"database/sql"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -23,6 +25,7 @@ func (s *AccountPgGatewaySuite) TestCreateAndGetByID_SaveSuccessfully() {
 	assert.NotNil(s.T(), actualAccount)
 	assert.Equal(s.T(), s.AccountOne.ID, actualAccount.ID)
 	assert.Equal(s.T(), s.AccountOne.Customer.ID, actualAccount.Customer.ID)
+	assert.Equal(s.T(), s.AccountOne.Currency, actualAccount.Currency)
 	assert.Equal(s.T(), s.AccountOne.Balance.String(), actualAccount.Balance.String())
 	assert.Equal(s.T(), s.AccountOne.CreatedAt, actualAccount.CreatedAt)
 	assert.Equal(s.T(), s.AccountOne.UpdatedAt, actualAccount.UpdatedAt)
@@ -65,6 +68,82 @@ func (s *AccountPgGatewaySuite) TestGetByID_GetSuccessfully() {
 	assert.Equal(s.T(), s.AccountTwo.UpdatedAt, actualAccountTwo.UpdatedAt)
 }
 
+func (s *AccountPgGatewaySuite) TestUpdateBalance_UpdateAndBumpVersion() {
+	expectedBalance := decimal.NewFromInt(1000)
+	_ = s.AccountPgGateway.Create(s.AccountOne)
+
+	err := s.AccountPgGateway.UpdateBalance(s.AccountOne.ID, expectedBalance, s.AccountOne.Version)
+	assert.Nil(s.T(), err)
+
+	actualAccount, err := s.AccountPgGateway.GetByID(s.AccountOne.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedBalance.String(), actualAccount.Balance.String())
+	assert.Equal(s.T(), s.AccountOne.Version+1, actualAccount.Version)
+}
+
+func (s *AccountPgGatewaySuite) TestUpdateBalance_FailDueToVersionConflict() {
+	_ = s.AccountPgGateway.Create(s.AccountOne)
+	_ = s.AccountPgGateway.UpdateBalance(s.AccountOne.ID, decimal.NewFromInt(1000), s.AccountOne.Version)
+
+	err := s.AccountPgGateway.UpdateBalance(s.AccountOne.ID, decimal.NewFromInt(2000), s.AccountOne.Version)
+
+	var conflict *gateway.VersionConflictError
+	assert.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), s.AccountOne.ID, conflict.ID)
+
+	actualAccount, err := s.AccountPgGateway.GetByID(s.AccountOne.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "1000", actualAccount.Balance.String())
+}
+
+func (s *AccountPgGatewaySuite) TestUpdateOverdraftLimit_UpdateAndBumpVersion() {
+	_ = s.AccountPgGateway.Create(s.AccountOne)
+
+	err := s.AccountPgGateway.UpdateOverdraftLimit(s.AccountOne.ID, decimal.NewFromInt(500), s.AccountOne.Version)
+	assert.Nil(s.T(), err)
+
+	actualAccount, err := s.AccountPgGateway.GetByID(s.AccountOne.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "500", actualAccount.OverdraftLimit.String())
+	assert.Equal(s.T(), s.AccountOne.Version+1, actualAccount.Version)
+
+	err = s.AccountPgGateway.UpdateOverdraftLimit(s.AccountOne.ID, decimal.NewFromInt(800), s.AccountOne.Version)
+
+	var conflict *gateway.VersionConflictError
+	assert.ErrorAs(s.T(), err, &conflict)
+}
+
+func (s *AccountPgGatewaySuite) TestUpdateTransferLimits_SetAndClearLimits() {
+	_ = s.AccountPgGateway.Create(s.AccountOne)
+	maxTransfer := decimal.NewFromInt(1000)
+	maxCount := int64(10)
+
+	err := s.AccountPgGateway.UpdateTransferLimits(s.AccountOne.ID, entity.TransferLimits{
+		MaxTransferAmount: &maxTransfer,
+		MaxDailyCount:     &maxCount,
+	}, s.AccountOne.Version)
+	assert.Nil(s.T(), err)
+
+	actualAccount, err := s.AccountPgGateway.GetByID(s.AccountOne.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "1000", actualAccount.TransferLimits.MaxTransferAmount.String())
+	assert.Nil(s.T(), actualAccount.TransferLimits.MaxDailyAmount)
+	assert.Equal(s.T(), int64(10), *actualAccount.TransferLimits.MaxDailyCount)
+	assert.Equal(s.T(), s.AccountOne.Version+1, actualAccount.Version)
+
+	err = s.AccountPgGateway.UpdateTransferLimits(s.AccountOne.ID, entity.TransferLimits{}, actualAccount.Version)
+	assert.Nil(s.T(), err)
+
+	actualAccount, err = s.AccountPgGateway.GetByID(s.AccountOne.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), entity.TransferLimits{}, actualAccount.TransferLimits)
+}
+
 func (s *AccountPgGatewaySuite) TestGetByID_FetchEmpty() {
 	actualAccount, err := s.AccountPgGateway.GetByID(uuid.New())
 	expectedError := "sql: no rows in result set"
@@ -76,6 +155,7 @@ func (s *AccountPgGatewaySuite) TestGetByID_FetchEmpty() {
 
 type AccountPgGatewaySuite struct {
 	suite.Suite
+	DB               *sql.DB
 	AccountPgGateway *AccountPgGateway
 	AccountOne       *entity.Account
 	AccountTwo       *entity.Account
@@ -86,6 +166,7 @@ func (s *AccountPgGatewaySuite) SetupSuite() {
 	db, err := sql.Open("sqlite3", ":memory:")
 	s.Require().Nil(err)
 
+	s.DB = db
 	s.AccountPgGateway = NewAccountPgGateway(db)
 
 	query := `CREATE TABLE customers (
@@ -98,10 +179,19 @@ func (s *AccountPgGatewaySuite) SetupSuite() {
 	_, err = s.AccountPgGateway.DB.Exec(query)
 	s.Require().Nil(err)
 
+	_, err = s.AccountPgGateway.DB.Exec("CREATE UNIQUE INDEX customers_email_key ON customers (lower(email))")
+	s.Require().Nil(err)
+
 	query = `CREATE TABLE accounts (
 				id BINARY(16) PRIMARY KEY,
 				customer_id BINARY(16) NOT NULL,
-				balance DECIMAL(12, 2),
+				currency CHAR(3) NOT NULL,
+				balance DECIMAL(19, 4),
+				overdraft_limit DECIMAL(19, 4) NOT NULL DEFAULT 0,
+				max_transfer_amount DECIMAL(19, 4),
+				max_daily_amount DECIMAL(19, 4),
+				max_daily_count INTEGER,
+				version INTEGER NOT NULL DEFAULT 0,
 				created_at DATETIME,
 				updated_at DATETIME
 		     )`
@@ -118,15 +208,15 @@ func (s *AccountPgGatewaySuite) SetupSuite() {
 
 func (s *AccountPgGatewaySuite) SetupTest() {
 	var err error
-	s.AccountOne, err = entity.NewAccount(s.Customer)
+	s.AccountOne, err = entity.NewAccount(s.Customer, entity.BRL)
 	s.Require().Nil(err)
 
-	s.AccountTwo, err = entity.NewAccount(s.Customer)
+	s.AccountTwo, err = entity.NewAccount(s.Customer, entity.BRL)
 	s.Require().Nil(err)
 }
 
 func (s *AccountPgGatewaySuite) TearDownSuite() {
-	defer s.AccountPgGateway.DB.Close()
+	defer s.DB.Close()
 	_, _ = s.AccountPgGateway.DB.Exec("DROP TABLE accounts")
 	_, _ = s.AccountPgGateway.DB.Exec("DROP TABLE customers")
 }
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+)
+
+type AuditPgGateway struct {
+	DB Executor
+}
+
+func NewAuditPgGateway(db Executor) *AuditPgGateway {
+	return &AuditPgGateway{DB: db}
+}
+
+func (a AuditPgGateway) Create(entry *entity.AuditEntry) error {
+	query := `INSERT INTO audit_entries (id, entity, entity_id, action, old_value, new_value, reason, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(
+		entry.ID,
+		entry.Entity,
+		entry.EntityID,
+		entry.Action,
+		entry.OldValue,
+		entry.NewValue,
+		entry.Reason,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
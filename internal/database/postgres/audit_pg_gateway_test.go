@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestNewAuditPgDBTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditPgGatewaySuite))
+}
+
+func (s *AuditPgGatewaySuite) TestCreate_SaveSuccessfully() {
+	entry := entity.NewAuditEntry("account", uuid.New(), "overdraft_limit_changed", "0", "500", "business plan")
+
+	err := s.AuditPgGateway.Create(entry)
+	assert.Nil(s.T(), err)
+
+	var action, oldValue, newValue, reason string
+	err = s.DB.QueryRow(`SELECT action, old_value, new_value, reason FROM audit_entries WHERE id = $1`, entry.ID).
+		Scan(&action, &oldValue, &newValue, &reason)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "overdraft_limit_changed", action)
+	assert.Equal(s.T(), "0", oldValue)
+	assert.Equal(s.T(), "500", newValue)
+	assert.Equal(s.T(), "business plan", reason)
+}
+
+type AuditPgGatewaySuite struct {
+	suite.Suite
+	DB             *sql.DB
+	AuditPgGateway *AuditPgGateway
+}
+
+func (s *AuditPgGatewaySuite) SetupSuite() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	s.Require().Nil(err)
+
+	s.DB = db
+	s.AuditPgGateway = NewAuditPgGateway(db)
+
+	query := `CREATE TABLE audit_entries (
+				id BINARY(16) PRIMARY KEY,
+				entity VARCHAR(64) NOT NULL,
+				entity_id BINARY(16) NOT NULL,
+				action VARCHAR(64) NOT NULL,
+				old_value TEXT NOT NULL,
+				new_value TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				created_at DATETIME
+		     )`
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
+}
+
+func (s *AuditPgGatewaySuite) TearDownSuite() {
+	defer s.DB.Close()
+	_, _ = s.DB.Exec("DROP TABLE audit_entries")
+}
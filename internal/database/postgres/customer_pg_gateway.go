@@ -3,39 +3,74 @@ package postgres
 import (
 	"database/sql"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
+	"time"
 )
 
 type CustomerPgGatewayDB struct {
-	DB *sql.DB
+	DB Executor
 }
 
-func NewCustomerPgGateway(db *sql.DB) *CustomerPgGatewayDB {
+func NewCustomerPgGateway(db Executor) *CustomerPgGatewayDB {
 	return &CustomerPgGatewayDB{DB: db}
 }
 
+// Create reports a customer whose e-mail is already registered as a
+// DuplicateKeyError. Only customers_email_key is ignored on conflict, so any
+// other violation surfaces as is.
 func (c *CustomerPgGatewayDB) Create(customer *entity.Customer) error {
 	stmt, err := c.DB.Prepare(
-		"INSERT INTO customers (id, name, email, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)",
+		`INSERT INTO customers (id, name, email, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT ((lower(email))) DO NOTHING`,
 	)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(customer.ID, customer.Name, customer.Email, customer.CreatedAt, customer.UpdatedAt)
+	result, err := stmt.Exec(customer.ID, customer.Name, customer.Email, customer.CreatedAt, customer.UpdatedAt)
 	if err != nil {
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &gateway.DuplicateKeyError{Entity: "customer", Key: customer.Email}
+	}
+
 	return nil
 }
 
 func (c *CustomerPgGatewayDB) GetByID(ID uuid.UUID) (*entity.Customer, error) {
-	customer := &entity.Customer{}
 	query := `SELECT id, name, email, created_at, updated_at 
 				FROM customers 
  				WHERE id = $1`
+	return c.getCustomer(query, ID.String())
+}
+
+// GetByIDForUpdate locks the customer row until the surrounding transaction
+// ends, so concurrent updates can't overwrite each other's fields.
+func (c *CustomerPgGatewayDB) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	query := `SELECT id, name, email, created_at, updated_at
+				FROM customers
+				WHERE id = $1
+				FOR UPDATE`
+	return c.getCustomer(query, ID.String())
+}
+
+func (c *CustomerPgGatewayDB) GetByEmail(email string) (*entity.Customer, error) {
+	query := `SELECT id, name, email, created_at, updated_at
+				FROM customers
+				WHERE lower(email) = lower($1)`
+	return c.getCustomer(query, entity.NormalizeEmail(email))
+}
+
+func (c *CustomerPgGatewayDB) getCustomer(query string, args ...interface{}) (*entity.Customer, error) {
+	customer := &entity.Customer{}
 
 	stmt, err := c.DB.Prepare(query)
 	if err != nil {
@@ -43,7 +78,7 @@ func (c *CustomerPgGatewayDB) GetByID(ID uuid.UUID) (*entity.Customer, error) {
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRow(ID.String()).
+	err = stmt.QueryRow(args...).
 		Scan(
 			&customer.ID,
 			&customer.Name,
@@ -57,3 +92,33 @@ func (c *CustomerPgGatewayDB) GetByID(ID uuid.UUID) (*entity.Customer, error) {
 
 	return customer, nil
 }
+
+func (c *CustomerPgGatewayDB) Update(customer *entity.Customer) error {
+	query := `UPDATE customers SET name = $1, email = $2, updated_at = $3 WHERE id = $4`
+
+	stmt, err := c.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	updatedAt := time.Now().UTC()
+	result, err := stmt.Exec(customer.Name, customer.Email, updatedAt, customer.ID)
+	if isUniqueViolation(err) {
+		return &gateway.DuplicateKeyError{Entity: "customer", Key: customer.Email}
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	customer.UpdatedAt = updatedAt
+	return nil
+}
@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -43,15 +45,90 @@ func (s *CustomerPgGatewaySuite) TestGetByID_FetchEmpty() {
 	assert.Nil(s.T(), actualCustomer)
 }
 
+func (s *CustomerPgGatewaySuite) TestUpdate_UpdateAndBumpUpdatedAt() {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	err := s.CustomerPgGateway.Create(customer)
+	assert.Nil(s.T(), err)
+	createdAt := customer.UpdatedAt
+
+	_ = customer.Update("alexandre", "alexandre@gmail.com")
+	err = s.CustomerPgGateway.Update(customer)
+
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), customer.UpdatedAt.After(createdAt))
+
+	actualCustomer, err := s.CustomerPgGateway.GetByID(customer.ID)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "alexandre", actualCustomer.Name)
+	assert.Equal(s.T(), "alexandre@gmail.com", actualCustomer.Email)
+	assert.Equal(s.T(), createdAt, actualCustomer.CreatedAt)
+	assert.Equal(s.T(), customer.UpdatedAt, actualCustomer.UpdatedAt)
+}
+
+func (s *CustomerPgGatewaySuite) TestUpdate_FailDueToUnknownCustomer() {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+
+	err := s.CustomerPgGateway.Update(customer)
+
+	assert.Equal(s.T(), sql.ErrNoRows, err)
+}
+
+func (s *CustomerPgGatewaySuite) TestCreate_FailDueToDuplicateEmail() {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	err := s.CustomerPgGateway.Create(customer)
+	assert.Nil(s.T(), err)
+
+	duplicate, _ := entity.NewCustomer("other alex", "alexandrebrunodias@gmail.com")
+	err = s.CustomerPgGateway.Create(duplicate)
+
+	var duplicateErr *gateway.DuplicateKeyError
+	assert.ErrorAs(s.T(), err, &duplicateErr)
+	assert.Equal(s.T(), "alexandrebrunodias@gmail.com", duplicateErr.Key)
+}
+
+func (s *CustomerPgGatewaySuite) TestCreate_FailDueToDuplicateIDWithoutBlamingTheEmail() {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	err := s.CustomerPgGateway.Create(customer)
+	assert.Nil(s.T(), err)
+
+	sameID, _ := entity.NewCustomer("other alex", "other@gmail.com")
+	sameID.ID = customer.ID
+	err = s.CustomerPgGateway.Create(sameID)
+
+	var duplicateErr *gateway.DuplicateKeyError
+	assert.NotNil(s.T(), err)
+	assert.False(s.T(), errors.As(err, &duplicateErr))
+}
+
+func (s *CustomerPgGatewaySuite) TestGetByEmail_MatchCaseInsensitively() {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	err := s.CustomerPgGateway.Create(customer)
+	assert.Nil(s.T(), err)
+
+	actualCustomer, err := s.CustomerPgGateway.GetByEmail(" AlexandreBrunoDias@Gmail.com")
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), customer.ID, actualCustomer.ID)
+}
+
+func (s *CustomerPgGatewaySuite) TestGetByEmail_FetchEmpty() {
+	actualCustomer, err := s.CustomerPgGateway.GetByEmail("nobody@gmail.com")
+
+	assert.Equal(s.T(), sql.ErrNoRows, err)
+	assert.Nil(s.T(), actualCustomer)
+}
+
 type CustomerPgGatewaySuite struct {
 	suite.Suite
+	DB                *sql.DB
 	CustomerPgGateway *CustomerPgGatewayDB
 }
 
-func (s *CustomerPgGatewaySuite) SetupSuite() {
+func (s *CustomerPgGatewaySuite) SetupTest() {
 	db, err := sql.Open("sqlite3", ":memory:")
 	s.Require().Nil(err)
 
+	s.DB = db
 	s.CustomerPgGateway = NewCustomerPgGateway(db)
 
 	stmt := `CREATE TABLE customers (
@@ -64,9 +141,12 @@ func (s *CustomerPgGatewaySuite) SetupSuite() {
 
 	_, err = s.CustomerPgGateway.DB.Exec(stmt)
 	s.Require().Nil(err)
+
+	_, err = s.CustomerPgGateway.DB.Exec("CREATE UNIQUE INDEX customers_email_key ON customers (lower(email))")
+	s.Require().Nil(err)
 }
 
-func (s *CustomerPgGatewaySuite) TearDownSuite() {
-	defer s.CustomerPgGateway.DB.Close()
+func (s *CustomerPgGatewaySuite) TearDownTest() {
+	defer s.DB.Close()
 	_, _ = s.CustomerPgGateway.DB.Exec("DROP TABLE customers")
 }
@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports when a write breaks a
+// unique index.
+const uniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"database/sql"
+)
+
+// Executor is implemented by both *sql.DB and *sql.Tx, so the same gateway
+// can run standalone or inside a unit of work transaction.
+type Executor interface {
+	Prepare(query string) (*sql.Stmt, error)
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"time"
+)
+
+type IdempotencyPgGateway struct {
+	DB Executor
+}
+
+func NewIdempotencyPgGateway(db Executor) *IdempotencyPgGateway {
+	return &IdempotencyPgGateway{DB: db}
+}
+
+// Create returns a *gateway.DuplicateKeyError when another request stored the
+// same key first. Postgres makes the insert wait for that request's
+// transaction, so the caller can retry and replay its outcome.
+func (i IdempotencyPgGateway) Create(key *entity.IdempotencyKey) error {
+	query := `INSERT INTO idempotency_keys (key, fingerprint, response, created_at, expires_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (key) DO NOTHING`
+
+	stmt, err := i.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(
+		key.Key,
+		key.Fingerprint,
+		string(key.Response),
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &gateway.DuplicateKeyError{Entity: "idempotency key", Key: key.Key}
+	}
+
+	return nil
+}
+
+func (i IdempotencyPgGateway) GetByKey(key string) (*entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+
+	query := `SELECT key, fingerprint, response, created_at, expires_at
+			  	FROM idempotency_keys
+			  	WHERE key = $1`
+	stmt, err := i.DB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRow(key).
+		Scan(
+			&idempotencyKey.Key,
+			&idempotencyKey.Fingerprint,
+			&idempotencyKey.Response,
+			&idempotencyKey.CreatedAt,
+			&idempotencyKey.ExpiresAt,
+		)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (i IdempotencyPgGateway) Delete(key string) error {
+	stmt, err := i.DB.Prepare("DELETE FROM idempotency_keys WHERE key = $1")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IdempotencyPgGateway) DeleteExpired(now time.Time) (int64, error) {
+	stmt, err := i.DB.Prepare("DELETE FROM idempotency_keys WHERE expires_at <= $1")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
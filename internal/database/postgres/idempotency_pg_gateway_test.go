@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+func TestNewIdempotencyPgDBTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyPgGatewaySuite))
+}
+
+func (s *IdempotencyPgGatewaySuite) TestCreateAndGetByKey_SaveSuccessfully() {
+	expectedKey, _ := entity.NewIdempotencyKey("key", "fingerprint", []byte(`{"id":"123"}`), time.Hour)
+
+	err := s.IdempotencyPgGateway.Create(expectedKey)
+	assert.Nil(s.T(), err)
+
+	actualKey, err := s.IdempotencyPgGateway.GetByKey(expectedKey.Key)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), expectedKey.Key, actualKey.Key)
+	assert.Equal(s.T(), expectedKey.Fingerprint, actualKey.Fingerprint)
+	assert.Equal(s.T(), expectedKey.Response, actualKey.Response)
+	assert.Equal(s.T(), expectedKey.ExpiresAt, actualKey.ExpiresAt)
+}
+
+func (s *IdempotencyPgGatewaySuite) TestCreate_FailDueToDuplicateKey() {
+	firstKey, _ := entity.NewIdempotencyKey("key", "fingerprint", []byte(`{}`), time.Hour)
+	secondKey, _ := entity.NewIdempotencyKey("key", "another fingerprint", []byte(`{}`), time.Hour)
+	_ = s.IdempotencyPgGateway.Create(firstKey)
+
+	err := s.IdempotencyPgGateway.Create(secondKey)
+
+	var duplicate *gateway.DuplicateKeyError
+	assert.ErrorAs(s.T(), err, &duplicate)
+
+	actualKey, err := s.IdempotencyPgGateway.GetByKey("key")
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), firstKey.Fingerprint, actualKey.Fingerprint)
+}
+
+func (s *IdempotencyPgGatewaySuite) TestDelete_FetchEmpty() {
+	key, _ := entity.NewIdempotencyKey("key", "fingerprint", []byte(`{}`), time.Hour)
+	_ = s.IdempotencyPgGateway.Create(key)
+
+	err := s.IdempotencyPgGateway.Delete(key.Key)
+	assert.Nil(s.T(), err)
+
+	actualKey, err := s.IdempotencyPgGateway.GetByKey(key.Key)
+
+	assert.Equal(s.T(), sql.ErrNoRows, err)
+	assert.Nil(s.T(), actualKey)
+}
+
+func (s *IdempotencyPgGatewaySuite) TestDeleteExpired_KeepUnexpiredKeys() {
+	expiredKey, _ := entity.NewIdempotencyKey("expired", "fingerprint", []byte(`{}`), time.Minute)
+	unexpiredKey, _ := entity.NewIdempotencyKey("unexpired", "fingerprint", []byte(`{}`), time.Hour)
+	_ = s.IdempotencyPgGateway.Create(expiredKey)
+	_ = s.IdempotencyPgGateway.Create(unexpiredKey)
+
+	deleted, err := s.IdempotencyPgGateway.DeleteExpired(time.Now().UTC().Add(10 * time.Minute))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(1), deleted)
+
+	_, err = s.IdempotencyPgGateway.GetByKey(expiredKey.Key)
+	assert.Equal(s.T(), sql.ErrNoRows, err)
+
+	actualKey, err := s.IdempotencyPgGateway.GetByKey(unexpiredKey.Key)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), unexpiredKey.Key, actualKey.Key)
+}
+
+type IdempotencyPgGatewaySuite struct {
+	suite.Suite
+	DB                   *sql.DB
+	IdempotencyPgGateway *IdempotencyPgGateway
+}
+
+func (s *IdempotencyPgGatewaySuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	s.Require().Nil(err)
+
+	s.DB = db
+	s.IdempotencyPgGateway = NewIdempotencyPgGateway(db)
+
+	query := `CREATE TABLE idempotency_keys (
+				key VARCHAR(255) PRIMARY KEY,
+				fingerprint VARCHAR(64) NOT NULL,
+				response TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL
+			 )`
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
+}
+
+func (s *IdempotencyPgGatewaySuite) TearDownTest() {
+	defer s.DB.Close()
+	_, _ = s.DB.Exec("DROP TABLE idempotency_keys")
+}
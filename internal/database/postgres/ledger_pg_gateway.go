@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+)
+
+type LedgerPgGateway struct {
+	DB Executor
+}
+
+func NewLedgerPgGateway(db Executor) *LedgerPgGateway {
+	return &LedgerPgGateway{DB: db}
+}
+
+func (l LedgerPgGateway) Create(entries ...*entity.LedgerEntry) error {
+	query := `INSERT INTO ledger_entries (id, transaction_id, account_id, direction, amount, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6)`
+
+	stmt, err := l.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		_, err = stmt.Exec(
+			entry.ID,
+			entry.TransactionID,
+			entry.AccountID,
+			entry.Direction,
+			entry.Amount,
+			entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetBalances recomputes every account balance from its ledger entries,
+// alongside the balance currently stored on the account.
+func (l LedgerPgGateway) GetBalances() ([]*entity.LedgerBalance, error) {
+	query := `SELECT a.id, a.balance,
+					COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END), 0)
+				FROM accounts a
+				LEFT JOIN ledger_entries e ON e.account_id = a.id
+				GROUP BY a.id, a.balance
+				ORDER BY a.id`
+
+	stmt, err := l.DB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*entity.LedgerBalance
+	for rows.Next() {
+		var balance entity.LedgerBalance
+		err = rows.Scan(&balance.AccountID, &balance.Balance, &balance.LedgerBalance)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, &balance)
+	}
+
+	return balances, rows.Err()
+}
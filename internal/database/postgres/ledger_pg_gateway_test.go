@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestNewLedgerPgDBTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerPgGatewaySuite))
+}
+
+func (s *LedgerPgGatewaySuite) TestCreateAndGetBalances_NoDrift() {
+	_ = s.FromAccount.Credit(decimal.NewFromInt(2000))
+	_ = NewAccountPgGateway(s.DB).Create(s.FromAccount)
+	_ = NewAccountPgGateway(s.DB).Create(s.ToAccount)
+	err := s.LedgerPgGateway.Create(&entity.LedgerEntry{
+		ID:        uuid.New(),
+		AccountID: s.FromAccount.ID,
+		Direction: entity.Credit,
+		Amount:    decimal.NewFromInt(2000),
+		CreatedAt: s.FromAccount.CreatedAt,
+	})
+	s.Require().Nil(err)
+
+	transaction, _ := entity.NewTransaction(s.FromAccount, s.ToAccount, decimal.NewFromInt(500), nil)
+	_ = NewAccountPgGateway(s.DB).UpdateBalance(s.FromAccount.ID, s.FromAccount.Balance, 0)
+	_ = NewAccountPgGateway(s.DB).UpdateBalance(s.ToAccount.ID, s.ToAccount.Balance, 0)
+
+	err = s.LedgerPgGateway.Create(transaction.LedgerEntries()...)
+	assert.Nil(s.T(), err)
+
+	balances, err := s.LedgerPgGateway.GetBalances()
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), balances, 2)
+	for _, balance := range balances {
+		assert.True(s.T(), balance.Drift().IsZero(), balance.AccountID)
+	}
+}
+
+func (s *LedgerPgGatewaySuite) TestGetBalances_ReportDrift() {
+	_ = NewAccountPgGateway(s.DB).Create(s.FromAccount)
+	_ = NewAccountPgGateway(s.DB).UpdateBalance(s.FromAccount.ID, decimal.NewFromInt(300), 0)
+
+	balances, err := s.LedgerPgGateway.GetBalances()
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), balances, 1)
+	assert.Equal(s.T(), s.FromAccount.ID, balances[0].AccountID)
+	assert.Equal(s.T(), "300", balances[0].Drift().String())
+}
+
+type LedgerPgGatewaySuite struct {
+	suite.Suite
+	DB              *sql.DB
+	LedgerPgGateway *LedgerPgGateway
+	FromAccount     *entity.Account
+	ToAccount       *entity.Account
+}
+
+func (s *LedgerPgGatewaySuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	s.Require().Nil(err)
+
+	s.DB = db
+	s.LedgerPgGateway = NewLedgerPgGateway(db)
+
+	query := `CREATE TABLE accounts (
+				id BINARY(16) PRIMARY KEY,
+				customer_id BINARY(16) NOT NULL,
+				currency CHAR(3) NOT NULL,
+				balance DECIMAL(19, 4),
+				overdraft_limit DECIMAL(19, 4) NOT NULL DEFAULT 0,
+				max_transfer_amount DECIMAL(19, 4),
+				max_daily_amount DECIMAL(19, 4),
+				max_daily_count INTEGER,
+				version INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME,
+				updated_at DATETIME
+		     )`
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
+
+	query = `CREATE TABLE ledger_entries (
+				id BINARY(16) PRIMARY KEY,
+				transaction_id BINARY(16),
+				account_id BINARY(16) NOT NULL,
+				direction VARCHAR(6) NOT NULL,
+				amount DECIMAL(14, 2) NOT NULL,
+				created_at DATETIME NOT NULL
+		     )`
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
+
+	customer, err := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	s.Require().Nil(err)
+
+	s.FromAccount, err = entity.NewAccount(customer, entity.BRL)
+	s.Require().Nil(err)
+
+	s.ToAccount, err = entity.NewAccount(customer, entity.BRL)
+	s.Require().Nil(err)
+}
+
+func (s *LedgerPgGatewaySuite) TearDownTest() {
+	defer s.DB.Close()
+	_, _ = s.DB.Exec("DROP TABLE ledger_entries")
+	_, _ = s.DB.Exec("DROP TABLE accounts")
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// DefaultOutboxClaimTimeout is how long a fetched message stays hidden from
+// other relays, long enough for a batch to be sent.
+const DefaultOutboxClaimTimeout = time.Minute
+
+type OutboxPgGateway struct {
+	DB           Executor
+	ClaimTimeout time.Duration
+}
+
+func NewOutboxPgGateway(db Executor) *OutboxPgGateway {
+	return &OutboxPgGateway{DB: db, ClaimTimeout: DefaultOutboxClaimTimeout}
+}
+
+func (o OutboxPgGateway) Create(event events.Event) error {
+	query := `INSERT INTO outbox (id, name, event_key, schema_version, payload, occurred_at, attempts, next_attempt_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	payload, err := json.Marshal(event.Content)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := o.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	_, err = stmt.Exec(
+		event.EID,
+		event.Name,
+		sql.NullString{String: event.Key, Valid: event.Key != ""},
+		event.SchemaVersion,
+		string(payload),
+		event.OccurredAt.UTC(),
+		0,
+		now,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchPending claims the messages it returns by pushing their next attempt
+// ClaimTimeout ahead. A relay fetching concurrently waits for the claiming
+// update and then skips the rows it claimed, as they are no longer due. A
+// message is only due once every earlier one sharing its key is delivered.
+func (o OutboxPgGateway) FetchPending(now time.Time, limit int) ([]*events.OutboxMessage, error) {
+	query := `UPDATE outbox SET next_attempt_at = $1
+				WHERE delivered_at IS NULL AND next_attempt_at <= $2 AND id IN (
+					SELECT o.id
+						FROM outbox o
+						WHERE o.delivered_at IS NULL AND o.next_attempt_at <= $2
+							AND NOT EXISTS (
+								SELECT 1
+									FROM outbox e
+									WHERE e.event_key = o.event_key AND e.delivered_at IS NULL
+										AND (e.created_at < o.created_at OR (e.created_at = o.created_at AND e.id < o.id))
+							)
+						ORDER BY o.created_at, o.id
+						LIMIT $3
+				)
+				RETURNING id, name, event_key, schema_version, payload, occurred_at, attempts, next_attempt_at, last_error, created_at`
+
+	stmt, err := o.DB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(now.UTC().Add(o.ClaimTimeout), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*events.OutboxMessage
+	for rows.Next() {
+		var message events.OutboxMessage
+		var payload []byte
+		var key sql.NullString
+		var lastError sql.NullString
+
+		err = rows.Scan(
+			&message.ID,
+			&message.Event.Name,
+			&key,
+			&message.Event.SchemaVersion,
+			&payload,
+			&message.Event.OccurredAt,
+			&message.Attempts,
+			&message.NextAttemptAt,
+			&lastError,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		message.Event.EID = message.ID
+		message.Event.Key = key.String
+		message.Event.Content = json.RawMessage(payload)
+		message.LastError = lastError.String
+		messages = append(messages, &message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the order of the subquery.
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return bytes.Compare(messages[i].ID[:], messages[j].ID[:]) < 0
+	})
+	return messages, nil
+}
+
+func (o OutboxPgGateway) MarkDelivered(ID uuid.UUID, deliveredAt time.Time) error {
+	query := `UPDATE outbox SET delivered_at = $1 WHERE id = $2`
+
+	stmt, err := o.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(deliveredAt.UTC(), ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o OutboxPgGateway) MarkFailed(ID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE outbox SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4`
+
+	stmt, err := o.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(attempts, nextAttemptAt.UTC(), lastError, ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
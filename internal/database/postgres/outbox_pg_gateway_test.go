@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+func TestNewOutboxPgDBTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxPgGatewaySuite))
+}
+
+func (s *OutboxPgGatewaySuite) TestCreateAndFetchPending_FetchSuccessfully() {
+	expectedEvent := events.NewEvent("wallet.core.transaction.created", map[string]string{"id": "123"})
+
+	err := s.OutboxPgGateway.Create(*expectedEvent)
+	assert.Nil(s.T(), err)
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), expectedEvent.EID, messages[0].ID)
+	assert.Equal(s.T(), expectedEvent.EID, messages[0].Event.EID)
+	assert.Equal(s.T(), expectedEvent.Name, messages[0].Event.Name)
+	assert.Equal(s.T(), json.RawMessage(`{"id":"123"}`), messages[0].Event.Content)
+	assert.Equal(s.T(), 0, messages[0].Attempts)
+	assert.Empty(s.T(), messages[0].Event.Key)
+}
+
+func (s *OutboxPgGatewaySuite) TestCreateAndFetchPending_KeepKey() {
+	expectedEvent := events.NewEvent("wallet.core.transaction.created", nil).WithKey("account-id")
+
+	err := s.OutboxPgGateway.Create(*expectedEvent)
+	assert.Nil(s.T(), err)
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), "account-id", messages[0].Event.Key)
+}
+
+func (s *OutboxPgGatewaySuite) TestCreateAndFetchPending_KeepSchemaVersion() {
+	expectedEvent := events.NewEvent("wallet.core.transaction.created", nil)
+	expectedEvent.SchemaVersion = 2
+
+	err := s.OutboxPgGateway.Create(*expectedEvent)
+	assert.Nil(s.T(), err)
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), 2, messages[0].Event.SchemaVersion)
+}
+
+func (s *OutboxPgGatewaySuite) TestMarkDelivered_NotFetchedAnymore() {
+	event := events.NewEvent("wallet.core.transaction.created", nil)
+	_ = s.OutboxPgGateway.Create(*event)
+
+	err := s.OutboxPgGateway.MarkDelivered(event.EID, time.Now().UTC())
+	assert.Nil(s.T(), err)
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), messages)
+}
+
+func (s *OutboxPgGatewaySuite) TestMarkFailed_RescheduledWithAttempts() {
+	expectedError := "broker unavailable"
+	nextAttemptAt := time.Now().UTC().Add(time.Minute)
+	event := events.NewEvent("wallet.core.transaction.created", nil)
+	_ = s.OutboxPgGateway.Create(*event)
+
+	err := s.OutboxPgGateway.MarkFailed(event.EID, 1, nextAttemptAt, expectedError)
+	assert.Nil(s.T(), err)
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), messages)
+
+	messages, err = s.OutboxPgGateway.FetchPending(nextAttemptAt.Add(time.Second), 10)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), 1, messages[0].Attempts)
+	assert.Equal(s.T(), expectedError, messages[0].LastError)
+}
+
+func (s *OutboxPgGatewaySuite) TestFetchPending_ClaimFetchedMessages() {
+	event := events.NewEvent("wallet.core.transaction.created", nil)
+	_ = s.OutboxPgGateway.Create(*event)
+	now := time.Now().UTC()
+
+	messages, err := s.OutboxPgGateway.FetchPending(now, 10)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+
+	messages, err = s.OutboxPgGateway.FetchPending(now, 10)
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), messages)
+
+	messages, err = s.OutboxPgGateway.FetchPending(now.Add(DefaultOutboxClaimTimeout+time.Second), 10)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+}
+
+func (s *OutboxPgGatewaySuite) TestFetchPending_HoldBackMessagesBehindFailedOneWithSameKey() {
+	failed := events.NewEvent("wallet.core.transaction.created", nil).WithKey("account-id")
+	sameKey := events.NewEvent("wallet.core.account.balance_updated", nil).WithKey("account-id")
+	otherKey := events.NewEvent("wallet.core.transaction.created", nil).WithKey("other-account-id")
+	for _, event := range []*events.Event{failed, sameKey, otherKey} {
+		s.Require().Nil(s.OutboxPgGateway.Create(*event))
+		time.Sleep(time.Millisecond)
+	}
+
+	nextAttemptAt := time.Now().UTC().Add(time.Minute)
+	s.Require().Nil(s.OutboxPgGateway.MarkFailed(failed.EID, 1, nextAttemptAt, "broker unavailable"))
+
+	messages, err := s.OutboxPgGateway.FetchPending(time.Now().UTC(), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), otherKey.EID, messages[0].ID)
+
+	s.Require().Nil(s.OutboxPgGateway.MarkDelivered(otherKey.EID, time.Now().UTC()))
+	messages, err = s.OutboxPgGateway.FetchPending(nextAttemptAt.Add(time.Second), 10)
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), failed.EID, messages[0].ID)
+}
+
+type OutboxPgGatewaySuite struct {
+	suite.Suite
+	DB              *sql.DB
+	OutboxPgGateway *OutboxPgGateway
+}
+
+func (s *OutboxPgGatewaySuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	s.Require().Nil(err)
+
+	s.DB = db
+	s.OutboxPgGateway = NewOutboxPgGateway(db)
+
+	query := `CREATE TABLE outbox (
+				id BINARY(16) PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				event_key TEXT,
+				schema_version INTEGER NOT NULL DEFAULT 1,
+				payload TEXT NOT NULL,
+				occurred_at DATETIME NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at DATETIME NOT NULL,
+				delivered_at DATETIME,
+				last_error TEXT,
+				created_at DATETIME NOT NULL
+			 )`
+
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
+}
+
+func (s *OutboxPgGatewaySuite) TearDownTest() {
+	defer s.DB.Close()
+	_, _ = s.DB.Exec("DROP TABLE outbox")
+}
@@ -2,21 +2,26 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
 )
 
 type TransactionPgGateway struct {
-	DB *sql.DB
+	DB Executor
 }
 
-func NewTransactionPgGateway(db *sql.DB) *TransactionPgGateway {
+func NewTransactionPgGateway(db Executor) *TransactionPgGateway {
 	return &TransactionPgGateway{DB: db}
 }
 
 func (a TransactionPgGateway) Create(transaction *entity.Transaction) error {
-	query := `INSERT INTO transactions (id, from_account_id, to_account_id, amount, created_at) 
-				VALUES ($1, $2, $3, $4, $5)`
+	query := `INSERT INTO transactions (id, from_account_id, to_account_id, status, amount, destination_amount, fx_rate, refunded_amount, refunded_destination_amount, reversal_of, failure_reason, created_at) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 	stmt, err := a.DB.Prepare(query)
 	if err != nil {
 		return err
@@ -27,7 +32,14 @@ func (a TransactionPgGateway) Create(transaction *entity.Transaction) error {
 		transaction.ID,
 		transaction.FromAccount.ID,
 		transaction.ToAccount.ID,
+		transaction.Status,
 		transaction.Amount,
+		transaction.DestinationAmount,
+		transaction.FXRate,
+		transaction.RefundedAmount,
+		transaction.RefundedDestinationAmount,
+		transaction.ReversalOf,
+		sql.NullString{String: transaction.FailureReason, Valid: transaction.FailureReason != ""},
 		transaction.CreatedAt,
 	)
 	if err != nil {
@@ -37,16 +49,75 @@ func (a TransactionPgGateway) Create(transaction *entity.Transaction) error {
 	return nil
 }
 
+// Update persists the status and refunded amounts of a transaction, the only
+// fields allowed to change after it is created.
+func (a TransactionPgGateway) Update(transaction *entity.Transaction) error {
+	query := `UPDATE transactions SET status = $1, refunded_amount = $2, refunded_destination_amount = $3 WHERE id = $4`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(transaction.Status, transaction.RefundedAmount, transaction.RefundedDestinationAmount, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SumOutgoing counts and sums the transfers accountID sent since the given
+// time. Reversals are left out, since they give money back rather than send
+// it, and so are failed transfers, which never moved any.
+func (a TransactionPgGateway) SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(amount), 0)
+				FROM transactions
+				WHERE from_account_id = $1 AND reversal_of IS NULL AND status <> 'failed' AND created_at >= $2`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var totals entity.TransferTotals
+	err = stmt.QueryRow(accountID, since).Scan(&totals.Count, &totals.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
 func (a TransactionPgGateway) GetByID(ID uuid.UUID) (*entity.Transaction, error) {
+	query := `SELECT id, from_account_id, to_account_id, status, amount, destination_amount, fx_rate, refunded_amount, refunded_destination_amount, reversal_of, failure_reason, created_at
+			  	FROM transactions
+			  	WHERE id = $1`
+	return a.getTransaction(query, ID)
+}
+
+// GetByIDForUpdate locks the transaction row until the surrounding
+// transaction ends, so concurrent reversals can't refund it twice.
+func (a TransactionPgGateway) GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error) {
+	query := `SELECT id, from_account_id, to_account_id, status, amount, destination_amount, fx_rate, refunded_amount, refunded_destination_amount, reversal_of, failure_reason, created_at
+			  	FROM transactions
+			  	WHERE id = $1
+			  	FOR UPDATE`
+	return a.getTransaction(query, ID)
+}
+
+func (a TransactionPgGateway) getTransaction(query string, ID uuid.UUID) (*entity.Transaction, error) {
 	var transaction entity.Transaction
 	var fromAccount entity.Account
 	var toAccount entity.Account
+	var fxRate decimal.NullDecimal
+	var reversalOf uuid.NullUUID
+	var failureReason sql.NullString
 	transaction.FromAccount = &fromAccount
 	transaction.ToAccount = &toAccount
 
-	query := `SELECT id, from_account_id, to_account_id, amount, created_at
-			  	FROM transactions
-			  	WHERE id = $1`
 	stmt, err := a.DB.Prepare(query)
 	if err != nil {
 		return nil, err
@@ -59,12 +130,144 @@ func (a TransactionPgGateway) GetByID(ID uuid.UUID) (*entity.Transaction, error)
 			&transaction.ID,
 			&transaction.FromAccount.ID,
 			&transaction.ToAccount.ID,
+			&transaction.Status,
 			&transaction.Amount,
+			&transaction.DestinationAmount,
+			&fxRate,
+			&transaction.RefundedAmount,
+			&transaction.RefundedDestinationAmount,
+			&reversalOf,
+			&failureReason,
 			&transaction.CreatedAt,
 		)
 	if err != nil {
 		return nil, err
 	}
 
+	if fxRate.Valid {
+		transaction.FXRate = &fxRate.Decimal
+	}
+	if reversalOf.Valid {
+		transaction.ReversalOf = &reversalOf.UUID
+	}
+	transaction.FailureReason = failureReason.String
+
 	return &transaction, err
 }
+
+// ListByAccount picks the page with every filter applied first, then anchors
+// the running balances on the current balance of the account minus what the
+// transactions after each entry moved. Only transactions from the oldest entry
+// of the page on are summed, including the ones filtered out in between, so
+// each page shows the same balances no matter how it was filtered and later
+// pages don't cost more than the transactions since. Failed transfers moved
+// nothing and are left out.
+func (a TransactionPgGateway) ListByAccount(filter gateway.StatementFilter) ([]*entity.StatementEntry, error) {
+	args := []interface{}{filter.AccountID}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"(from_account_id = $1 OR to_account_id = $1)", "status <> 'failed'"}
+	switch filter.Direction {
+	case entity.Outgoing:
+		conditions = append(conditions, "from_account_id = $1")
+	case entity.Incoming:
+		conditions = append(conditions, "to_account_id = $1")
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+param(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < "+param(*filter.To))
+	}
+	if filter.After != nil {
+		createdAt := param(filter.After.CreatedAt)
+		conditions = append(conditions, fmt.Sprintf(
+			"(created_at < %s OR (created_at = %s AND id < %s))",
+			createdAt, createdAt, param(filter.After.ID),
+		))
+	}
+
+	query := `WITH page AS (
+					SELECT id, created_at
+					FROM transactions
+					WHERE ` + strings.Join(conditions, " AND ") + `
+					ORDER BY created_at DESC, id DESC
+					LIMIT ` + param(filter.Limit) + `
+				),
+				oldest AS (
+					SELECT id, created_at FROM page ORDER BY created_at, id LIMIT 1
+				)
+				SELECT id, from_account_id, to_account_id, status, amount, destination_amount, fx_rate, refunded_amount, reversal_of, created_at, running_balance
+				FROM (
+					SELECT t.id, t.from_account_id, t.to_account_id, t.status, t.amount, t.destination_amount, t.fx_rate, t.refunded_amount, t.reversal_of, t.created_at,
+						a.balance - COALESCE(SUM(
+							CASE WHEN t.status IN ('completed', 'reversed') THEN
+								(CASE WHEN t.to_account_id = $1 THEN t.destination_amount ELSE 0 END) -
+								(CASE WHEN t.from_account_id = $1 THEN t.amount ELSE 0 END)
+							ELSE 0 END
+						) OVER (ORDER BY t.created_at DESC, t.id DESC ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0) AS running_balance
+					FROM transactions t
+					JOIN oldest o ON t.created_at > o.created_at OR (t.created_at = o.created_at AND t.id >= o.id)
+					JOIN accounts a ON a.id = $1
+					WHERE (t.from_account_id = $1 OR t.to_account_id = $1) AND t.status <> 'failed'
+				) since_oldest
+				WHERE id IN (SELECT id FROM page)
+				ORDER BY created_at DESC, id DESC`
+
+	stmt, err := a.DB.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*entity.StatementEntry
+	for rows.Next() {
+		var transaction entity.Transaction
+		var fromAccount entity.Account
+		var toAccount entity.Account
+		var fxRate decimal.NullDecimal
+		var reversalOf uuid.NullUUID
+		transaction.FromAccount = &fromAccount
+		transaction.ToAccount = &toAccount
+		entry := &entity.StatementEntry{Transaction: &transaction, Direction: entity.Incoming}
+
+		err = rows.Scan(
+			&transaction.ID,
+			&transaction.FromAccount.ID,
+			&transaction.ToAccount.ID,
+			&transaction.Status,
+			&transaction.Amount,
+			&transaction.DestinationAmount,
+			&fxRate,
+			&transaction.RefundedAmount,
+			&reversalOf,
+			&transaction.CreatedAt,
+			&entry.RunningBalance,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if fxRate.Valid {
+			transaction.FXRate = &fxRate.Decimal
+		}
+		if reversalOf.Valid {
+			transaction.ReversalOf = &reversalOf.UUID
+		}
+		if transaction.FromAccount.ID == filter.AccountID {
+			entry.Direction = entity.Outgoing
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
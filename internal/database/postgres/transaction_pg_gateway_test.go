@@ -3,11 +3,13 @@ package postgres
 import (
 	"database/sql"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 func TestNewTransactionPgDBTestSuite(t *testing.T) {
@@ -20,7 +22,7 @@ func (s *TransactionPgGatewaySuite) TestCreate_SaveSuccessfully() {
 
 	_ = s.FromAccount.Credit(fromAccountInitialBalance)
 
-	expectedTransaction, _ := entity.NewTransaction(s.FromAccount, s.ToAccount, expectedAmount)
+	expectedTransaction, _ := entity.NewTransaction(s.FromAccount, s.ToAccount, expectedAmount, nil)
 	err := s.TransactionPgGateway.Create(expectedTransaction)
 	assert.Nil(s.T(), err)
 
@@ -30,11 +32,91 @@ func (s *TransactionPgGatewaySuite) TestCreate_SaveSuccessfully() {
 	assert.NotNil(s.T(), actualTransaction)
 	assert.Equal(s.T(), expectedTransaction.ID, actualTransaction.ID)
 	assert.Equal(s.T(), expectedAmount, actualTransaction.Amount)
+	assert.Equal(s.T(), expectedAmount.String(), actualTransaction.DestinationAmount.String())
+	assert.Nil(s.T(), actualTransaction.FXRate)
 	assert.Equal(s.T(), s.FromAccount.ID, actualTransaction.FromAccount.ID)
 	assert.Equal(s.T(), s.ToAccount.ID, actualTransaction.ToAccount.ID)
+	assert.Equal(s.T(), entity.Completed, actualTransaction.Status)
 	assert.Equal(s.T(), expectedTransaction.CreatedAt, actualTransaction.CreatedAt)
 }
 
+func (s *TransactionPgGatewaySuite) TestCreate_SaveConversionBetweenCurrencies() {
+	_ = s.FromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(s.ToAccount.Customer, entity.USD)
+	conversion := &entity.Conversion{Rate: decimal.RequireFromString("0.2"), Rounding: entity.RoundHalfEven}
+
+	expectedTransaction, _ := entity.NewTransaction(s.FromAccount, toAccount, decimal.NewFromInt(1000), conversion)
+	s.Require().Nil(s.TransactionPgGateway.Create(expectedTransaction))
+	s.Require().Nil(NewAccountPgGateway(s.DB).Create(toAccount))
+
+	actualTransaction, err := s.TransactionPgGateway.GetByID(expectedTransaction.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "1000", actualTransaction.Amount.String())
+	assert.Equal(s.T(), "200", actualTransaction.DestinationAmount.String())
+	assert.Equal(s.T(), "0.2", actualTransaction.FXRate.String())
+
+	entries, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: toAccount.ID,
+		Limit:     10,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), "200", entries[0].RunningBalance.String())
+}
+
+func (s *TransactionPgGatewaySuite) TestCreateReversalAndUpdate_SaveSuccessfully() {
+	_ = s.FromAccount.Credit(decimal.NewFromInt(2000))
+	original, _ := entity.NewTransaction(s.FromAccount, s.ToAccount, decimal.NewFromInt(1000), nil)
+	_ = s.TransactionPgGateway.Create(original)
+
+	reversal, _ := entity.NewReversal(original, decimal.NewFromInt(400))
+	err := s.TransactionPgGateway.Create(reversal)
+	assert.Nil(s.T(), err)
+
+	err = s.TransactionPgGateway.Update(original)
+	assert.Nil(s.T(), err)
+
+	actualOriginal, err := s.TransactionPgGateway.GetByID(original.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), entity.Reversed, actualOriginal.Status)
+	assert.Equal(s.T(), "400", actualOriginal.RefundedAmount.String())
+	assert.Equal(s.T(), "400", actualOriginal.RefundedDestinationAmount.String())
+	assert.Nil(s.T(), actualOriginal.ReversalOf)
+
+	actualReversal, err := s.TransactionPgGateway.GetByID(reversal.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), original.ID, *actualReversal.ReversalOf)
+	assert.Equal(s.T(), s.ToAccount.ID, actualReversal.FromAccount.ID)
+	assert.Equal(s.T(), s.FromAccount.ID, actualReversal.ToAccount.ID)
+}
+
+func (s *TransactionPgGatewaySuite) TestCreate_SaveFailedTransactionWithItsReason() {
+	_, err := entity.NewTransaction(s.FromAccount, s.ToAccount, decimal.NewFromInt(1000), nil)
+	var failed *entity.TransactionFailedError
+	s.Require().ErrorAs(err, &failed)
+
+	err = s.TransactionPgGateway.Create(failed.Transaction)
+	assert.Nil(s.T(), err)
+
+	actualTransaction, err := s.TransactionPgGateway.GetByID(failed.Transaction.ID)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), entity.Failed, actualTransaction.Status)
+	assert.Equal(s.T(), entity.FailureInsufficientFunds, actualTransaction.FailureReason)
+
+	entries, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.FromAccount.ID,
+		Limit:     10,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), entries)
+}
+
 func (s *TransactionPgGatewaySuite) TestCreate_FailDueInvalidAccount() {
 	expectedPanicMessage := "runtime error: invalid memory address or nil pointer dereference"
 	assert.Panicsf(s.T(), func() {
@@ -56,8 +138,140 @@ func (s *TransactionPgGatewaySuite) TestGetByID_FetchEmpty() {
 	assert.Nil(s.T(), actualAccount)
 }
 
+func (s *TransactionPgGatewaySuite) TestListByAccount_ComputeRunningBalance() {
+	transactions := s.createStatement()
+
+	entries, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.FromAccount.ID,
+		Limit:     10,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), entries, 3)
+	assert.Equal(s.T(), transactions[2].ID, entries[0].Transaction.ID)
+	assert.Equal(s.T(), entity.Outgoing, entries[0].Direction)
+	assert.Equal(s.T(), "1400", entries[0].RunningBalance.String())
+	assert.Equal(s.T(), transactions[1].ID, entries[1].Transaction.ID)
+	assert.Equal(s.T(), entity.Incoming, entries[1].Direction)
+	assert.Equal(s.T(), "1700", entries[1].RunningBalance.String())
+	assert.Equal(s.T(), transactions[0].ID, entries[2].Transaction.ID)
+	assert.Equal(s.T(), "1500", entries[2].RunningBalance.String())
+}
+
+func (s *TransactionPgGatewaySuite) TestListByAccount_FilterByDirectionAndDate() {
+	transactions := s.createStatement()
+
+	entries, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.FromAccount.ID,
+		Direction: entity.Outgoing,
+		Limit:     10,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), entries, 2)
+	assert.Equal(s.T(), transactions[2].ID, entries[0].Transaction.ID)
+	assert.Equal(s.T(), "1400", entries[0].RunningBalance.String())
+	// The incoming transfer in between is filtered out but still counted.
+	assert.Equal(s.T(), transactions[0].ID, entries[1].Transaction.ID)
+	assert.Equal(s.T(), "1500", entries[1].RunningBalance.String())
+
+	from := transactions[1].CreatedAt
+	to := transactions[2].CreatedAt
+	entries, err = s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.FromAccount.ID,
+		From:      &from,
+		To:        &to,
+		Limit:     10,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), entries, 1)
+	assert.Equal(s.T(), transactions[1].ID, entries[0].Transaction.ID)
+	assert.Equal(s.T(), "1700", entries[0].RunningBalance.String())
+}
+
+func (s *TransactionPgGatewaySuite) TestListByAccount_PaginateWithCursor() {
+	transactions := s.createStatement()
+
+	firstPage, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.ToAccount.ID,
+		Limit:     2,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), firstPage, 2)
+
+	last := firstPage[1].Transaction
+	secondPage, err := s.TransactionPgGateway.ListByAccount(gateway.StatementFilter{
+		AccountID: s.ToAccount.ID,
+		After:     &gateway.StatementCursor{CreatedAt: last.CreatedAt, ID: last.ID},
+		Limit:     2,
+	})
+
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), secondPage, 1)
+	assert.Equal(s.T(), transactions[0].ID, secondPage[0].Transaction.ID)
+	assert.Equal(s.T(), entity.Incoming, secondPage[0].Direction)
+	assert.Equal(s.T(), "500", secondPage[0].RunningBalance.String())
+}
+
+func (s *TransactionPgGatewaySuite) TestSumOutgoing_LeaveOutReversalsAndOlderTransfers() {
+	transactions := s.createStatement()
+	reversal, err := entity.NewReversal(transactions[1], decimal.NewFromInt(50))
+	s.Require().Nil(err)
+	s.Require().Nil(s.TransactionPgGateway.Create(reversal))
+
+	totals, err := s.TransactionPgGateway.SumOutgoing(s.FromAccount.ID, transactions[0].CreatedAt)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(2), totals.Count)
+	assert.Equal(s.T(), "800", totals.Amount.String())
+
+	totals, err = s.TransactionPgGateway.SumOutgoing(s.FromAccount.ID, transactions[1].CreatedAt)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(1), totals.Count)
+	assert.Equal(s.T(), "300", totals.Amount.String())
+
+	totals, err = s.TransactionPgGateway.SumOutgoing(s.FromAccount.ID, time.Now().UTC())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(0), totals.Count)
+	assert.True(s.T(), totals.Amount.IsZero())
+}
+
+// createStatement moves money back and forth between both accounts, one
+// second apart, and stores the resulting balances.
+func (s *TransactionPgGatewaySuite) createStatement() []*entity.Transaction {
+	_ = s.FromAccount.Credit(decimal.NewFromInt(2000))
+	createdAt := time.Now().UTC().Add(-time.Hour)
+
+	var transactions []*entity.Transaction
+	for _, transfer := range []struct {
+		from   *entity.Account
+		to     *entity.Account
+		amount int64
+	}{
+		{s.FromAccount, s.ToAccount, 500},
+		{s.ToAccount, s.FromAccount, 200},
+		{s.FromAccount, s.ToAccount, 300},
+	} {
+		transaction, err := entity.NewTransaction(transfer.from, transfer.to, decimal.NewFromInt(transfer.amount), nil)
+		s.Require().Nil(err)
+		transaction.CreatedAt = createdAt.Add(time.Duration(len(transactions)) * time.Second)
+		s.Require().Nil(s.TransactionPgGateway.Create(transaction))
+		transactions = append(transactions, transaction)
+	}
+
+	s.Require().Nil(NewAccountPgGateway(s.DB).Create(s.FromAccount))
+	s.Require().Nil(NewAccountPgGateway(s.DB).Create(s.ToAccount))
+
+	return transactions
+}
+
 type TransactionPgGatewaySuite struct {
 	suite.Suite
+	DB                   *sql.DB
 	TransactionPgGateway *TransactionPgGateway
 	FromAccount          *entity.Account
 	ToAccount            *entity.Account
@@ -67,32 +281,57 @@ func (s *TransactionPgGatewaySuite) SetupSuite() {
 	db, err := sql.Open("sqlite3", ":memory:")
 	s.Require().Nil(err)
 
+	s.DB = db
 	s.TransactionPgGateway = NewTransactionPgGateway(db)
 
 	query := `CREATE TABLE transactions (
 				id BINARY(16) PRIMARY KEY,
 				from_account_id BINARY(16) NOT NULL,
 				to_account_id BINARY(16) NOT NULL,
-				amount DECIMAL(14, 2),
+				status VARCHAR(16) NOT NULL,
+				amount DECIMAL(19, 4),
+				destination_amount DECIMAL(19, 4) NOT NULL,
+				fx_rate DECIMAL(24, 12),
+				refunded_amount DECIMAL(19, 4) NOT NULL DEFAULT 0,
+				refunded_destination_amount DECIMAL(19, 4) NOT NULL DEFAULT 0,
+				reversal_of BINARY(16),
+				failure_reason TEXT,
 				created_at DATETIME
 		     )`
 
 	_, err = s.TransactionPgGateway.DB.Exec(query)
 	s.Require().Nil(err)
+
+	query = `CREATE TABLE accounts (
+				id BINARY(16) PRIMARY KEY,
+				customer_id BINARY(16) NOT NULL,
+				currency CHAR(3) NOT NULL,
+				balance DECIMAL(19, 4),
+				overdraft_limit DECIMAL(19, 4) NOT NULL DEFAULT 0,
+				max_transfer_amount DECIMAL(19, 4),
+				max_daily_amount DECIMAL(19, 4),
+				max_daily_count INTEGER,
+				version INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME,
+				updated_at DATETIME
+		     )`
+	_, err = s.DB.Exec(query)
+	s.Require().Nil(err)
 }
 
 func (s *TransactionPgGatewaySuite) SetupTest() {
 	customer, err := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	s.Require().Nil(err)
 
-	s.FromAccount, err = entity.NewAccount(customer)
+	s.FromAccount, err = entity.NewAccount(customer, entity.BRL)
 	s.Require().Nil(err)
 
-	s.ToAccount, err = entity.NewAccount(customer)
+	s.ToAccount, err = entity.NewAccount(customer, entity.BRL)
 	s.Require().Nil(err)
 }
 
 func (s *TransactionPgGatewaySuite) TearDownSuite() {
-	defer s.TransactionPgGateway.DB.Close()
+	defer s.DB.Close()
+	_, _ = s.TransactionPgGateway.DB.Exec("DROP TABLE accounts")
 	_, _ = s.TransactionPgGateway.DB.Exec("DROP TABLE transactions")
 }
@@ -1,39 +1,56 @@
 package entity
 
 import (
-	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"time"
 )
 
+// Account holds a balance in a single currency. OverdraftLimit is how far
+// below zero debits may take the balance; it is zero unless granted.
+// TransferLimits cap outgoing transfers and are left to the use cases to
+// check, as they need the transfers of the day.
 type Account struct {
-	ID        uuid.UUID
-	Customer  *Customer
-	Balance   decimal.Decimal
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID             uuid.UUID
+	Customer       *Customer
+	Currency       Currency
+	Balance        decimal.Decimal
+	OverdraftLimit decimal.Decimal
+	TransferLimits TransferLimits
+	Version        int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
-func NewAccount(customer *Customer) (*Account, error) {
+func NewAccount(customer *Customer, currency Currency) (*Account, error) {
 	if customer == nil {
-		return nil, errors.New("'customer' should not be null")
+		return nil, &ValidationError{Field: "customer", Message: "'customer' should not be null"}
+	}
+	if !currency.IsValid() {
+		return nil, unsupportedCurrency(string(currency))
 	}
 
 	now := time.Now().UTC()
 	return &Account{
-		ID:        uuid.New(),
-		Customer:  customer,
-		Balance:   decimal.Zero,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:             uuid.New(),
+		Customer:       customer,
+		Currency:       currency,
+		Balance:        decimal.Zero,
+		OverdraftLimit: decimal.Zero,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}, nil
 }
 
+// Credit adds amount to the balance. Credit and Debit both take amounts in
+// the account currency.
 func (a *Account) Credit(amount decimal.Decimal) error {
 	if amount.IsNegative() || amount.IsZero() {
-		return errors.New("credit a negative or zero 'amount' is not allowed")
+		return &ValidationError{Field: "amount", Message: "credit a negative or zero 'amount' is not allowed"}
+	}
+	if _, err := NewMoney(amount, a.Currency); err != nil {
+		return err
 	}
 	a.Balance = a.Balance.Add(amount)
 	return nil
@@ -41,16 +58,66 @@ func (a *Account) Credit(amount decimal.Decimal) error {
 
 func (a *Account) Debit(amount decimal.Decimal) error {
 	if amount.IsNegative() {
-		return errors.New("debit a negative or zero 'amount' is not allowed")
+		return &ValidationError{Field: "amount", Message: "debit a negative or zero 'amount' is not allowed"}
+	}
+	if _, err := NewMoney(amount, a.Currency); err != nil {
+		return err
 	}
 
-	if a.Balance.LessThan(amount) {
-		return errors.New(
-			fmt.Sprintf("customer %s has insufficient funds | balance: %s - debit amount: %s",
-				a.Customer.ID, a.Balance.String(), amount.String()),
-		)
+	if a.AvailableBalance().LessThan(amount) {
+		return &InsufficientFundsError{
+			CustomerID:     a.Customer.ID,
+			Balance:        a.Balance,
+			OverdraftLimit: a.OverdraftLimit,
+			Amount:         amount,
+		}
 	}
 
 	a.Balance = a.Balance.Sub(amount)
 	return nil
 }
+
+// AvailableBalance is what debits may still take, overdraft included.
+func (a *Account) AvailableBalance() decimal.Decimal {
+	return a.Balance.Add(a.OverdraftLimit)
+}
+
+// ChangeOverdraftLimit sets how far below zero the balance may go. A limit
+// can be lowered, but not below what the account already owes.
+func (a *Account) ChangeOverdraftLimit(limit decimal.Decimal) error {
+	if limit.IsNegative() {
+		return &ValidationError{Field: "overdraft_limit", Message: "'overdraft_limit' must not be negative"}
+	}
+	if !limit.Equal(limit.Truncate(a.Currency.MinorUnits())) {
+		return &ValidationError{
+			Field: "overdraft_limit",
+			Message: fmt.Sprintf(
+				"'overdraft_limit' %s has more than the %d decimal places %s allows",
+				limit.String(), a.Currency.MinorUnits(), a.Currency,
+			),
+		}
+	}
+	if a.Balance.Add(limit).IsNegative() {
+		return &UnprocessableError{
+			Code: "overdraft_limit_below_balance",
+			Message: fmt.Sprintf(
+				"overdraft limit %s is below the %s the account already owes",
+				limit.String(), a.Balance.Neg().String(),
+			),
+		}
+	}
+
+	a.OverdraftLimit = limit
+	return nil
+}
+
+// ChangeTransferLimits replaces every transfer limit of the account, leaving
+// them untouched when any is invalid.
+func (a *Account) ChangeTransferLimits(limits TransferLimits) error {
+	if err := limits.Validate(a.Currency); err != nil {
+		return err
+	}
+
+	a.TransferLimits = limits
+	return nil
+}
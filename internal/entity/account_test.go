@@ -10,7 +10,7 @@ import (
 func TestNewAccount_CreateSuccessfully(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	expectedBalance := decimal.Zero
-	account, err := NewAccount(expectedCustomer)
+	account, err := NewAccount(expectedCustomer, BRL)
 
 	assert.Nil(t, err)
 	assert.Equal(t, expectedCustomer.ID, account.Customer.ID)
@@ -19,17 +19,27 @@ func TestNewAccount_CreateSuccessfully(t *testing.T) {
 
 func TestNewAccount_FailDueToNilCustomer(t *testing.T) {
 	expectedErrorMessage := "'customer' should not be null"
-	account, err := NewAccount(nil)
+	account, err := NewAccount(nil, BRL)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, account)
 	assert.Equal(t, expectedErrorMessage, err.Error())
 }
 
+func TestNewAccount_FailDueToUnsupportedCurrency(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, err := NewAccount(expectedCustomer, "XYZ")
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "currency", validationError.Field)
+	assert.Nil(t, account)
+}
+
 func TestCreditAccount_Successfully(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	expectedBalance, _ := decimal.NewFromString("1000.32")
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 
 	err := account.Credit(expectedBalance)
 
@@ -42,7 +52,7 @@ func TestCreditAccount_FailDueZeroAmount(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	expectedErrorMessage := "credit a negative or zero 'amount' is not allowed"
 	expectedBalance := decimal.Zero
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 
 	err := account.Credit(expectedBalance)
 
@@ -54,7 +64,7 @@ func TestCreditAccount_FailDueNegativeAmount(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	expectedErrorMessage := "credit a negative or zero 'amount' is not allowed"
 	expectedBalance, _ := decimal.NewFromString("-1000.32")
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 
 	err := account.Credit(expectedBalance)
 
@@ -62,10 +72,22 @@ func TestCreditAccount_FailDueNegativeAmount(t *testing.T) {
 	assert.Equal(t, expectedErrorMessage, err.Error())
 }
 
+func TestCreditAccount_FailDueToAmountBeyondMinorUnits(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, JPY)
+
+	err := account.Credit(decimal.RequireFromString("100.5"))
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "amount", validationError.Field)
+	assert.True(t, account.Balance.IsZero())
+}
+
 func TestDebitAccount_Successfully(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	expectedBalance, _ := decimal.NewFromString("1000.32")
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 
 	err := account.Credit(expectedBalance)
 
@@ -76,7 +98,7 @@ func TestDebitAccount_Successfully(t *testing.T) {
 
 func TestDebitAccount_FailDueNegativeAmount(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 	expectedErrorMessage := "debit a negative or zero 'amount' is not allowed"
 
 	negativeAmount, _ := decimal.NewFromString("-1000.32")
@@ -88,7 +110,7 @@ func TestDebitAccount_FailDueNegativeAmount(t *testing.T) {
 
 func TestDebitAccount_FailDueToInsufficientFunds(t *testing.T) {
 	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
-	account, _ := NewAccount(expectedCustomer)
+	account, _ := NewAccount(expectedCustomer, BRL)
 
 	amount, _ := decimal.NewFromString("1000.32")
 	expectedErrorMessage :=
@@ -99,6 +121,75 @@ func TestDebitAccount_FailDueToInsufficientFunds(t *testing.T) {
 
 	err := account.Debit(amount)
 
-	assert.NotNil(t, err)
+	var insufficientFunds *InsufficientFundsError
+	assert.ErrorAs(t, err, &insufficientFunds)
+	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.Equal(t, amount, insufficientFunds.Amount)
+}
+
+func TestDebitAccount_OverdrawWithinLimit(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, BRL)
+	_ = account.Credit(decimal.NewFromInt(100))
+	_ = account.ChangeOverdraftLimit(decimal.NewFromInt(500))
+
+	err := account.Debit(decimal.NewFromInt(600))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "-500", account.Balance.String())
+	assert.True(t, account.AvailableBalance().IsZero())
+}
+
+func TestDebitAccount_FailDueToOverdraftLimitExceeded(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, BRL)
+	_ = account.ChangeOverdraftLimit(decimal.NewFromInt(500))
+	expectedErrorMessage := fmt.Sprintf(
+		"customer %s has insufficient funds | balance: 0 - overdraft limit: 500 - debit amount: 500.01",
+		expectedCustomer.ID,
+	)
+
+	err := account.Debit(decimal.RequireFromString("500.01"))
+
+	var insufficientFunds *InsufficientFundsError
+	assert.ErrorAs(t, err, &insufficientFunds)
 	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.True(t, account.Balance.IsZero())
+}
+
+func TestChangeOverdraftLimit_FailDueToNegativeLimit(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, BRL)
+
+	err := account.ChangeOverdraftLimit(decimal.NewFromInt(-1))
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "overdraft_limit", validationError.Field)
+	assert.True(t, account.OverdraftLimit.IsZero())
+}
+
+func TestChangeOverdraftLimit_FailDueToLimitBeyondMinorUnits(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, JPY)
+
+	err := account.ChangeOverdraftLimit(decimal.RequireFromString("100.5"))
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "overdraft_limit", validationError.Field)
+}
+
+func TestChangeOverdraftLimit_FailDueToLimitBelowOverdrawnBalance(t *testing.T) {
+	expectedCustomer, _ := NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := NewAccount(expectedCustomer, BRL)
+	_ = account.ChangeOverdraftLimit(decimal.NewFromInt(500))
+	_ = account.Debit(decimal.NewFromInt(300))
+
+	err := account.ChangeOverdraftLimit(decimal.NewFromInt(200))
+
+	var unprocessable *UnprocessableError
+	assert.ErrorAs(t, err, &unprocessable)
+	assert.Equal(t, "overdraft_limit_below_balance", unprocessable.Code)
+	assert.Equal(t, "500", account.OverdraftLimit.String())
 }
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// AuditEntry records a change made to an entity by hand, such as an operator
+// granting an overdraft, with the values before and after it and why it was
+// made.
+type AuditEntry struct {
+	ID        uuid.UUID
+	Entity    string
+	EntityID  uuid.UUID
+	Action    string
+	OldValue  string
+	NewValue  string
+	Reason    string
+	CreatedAt time.Time
+}
+
+func NewAuditEntry(entity string, entityID uuid.UUID, action string, oldValue string, newValue string, reason string) *AuditEntry {
+	return &AuditEntry{
+		ID:        uuid.New(),
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -1,7 +1,6 @@
 package entity
 
 import (
-	"errors"
 	"github.com/google/uuid"
 	"net/mail"
 	"strings"
@@ -16,12 +15,18 @@ type Customer struct {
 	UpdatedAt time.Time
 }
 
+// NormalizeEmail is the form e-mails are stored and compared in: two e-mails
+// differing only in case or surrounding blanks belong to the same customer.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func NewCustomer(name string, email string) (*Customer, error) {
 	now := time.Now().UTC()
 	customer := &Customer{
 		ID:        uuid.New(),
 		Name:      name,
-		Email:     email,
+		Email:     NormalizeEmail(email),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -35,18 +40,29 @@ func NewCustomer(name string, email string) (*Customer, error) {
 
 func (c *Customer) Validate() error {
 	if strings.TrimSpace(c.Name) == "" {
-		return errors.New("'name' should not be blank")
+		return &ValidationError{Field: "name", Message: "'name' should not be blank"}
 	}
 
-	if _, err := mail.ParseAddress(c.Email); err != nil {
-		return errors.New("'email' is invalid")
+	// A bare address only: "Name <address>" forms would escape uniqueness.
+	if address, err := mail.ParseAddress(c.Email); err != nil || address.Address != c.Email {
+		return &ValidationError{Field: "email", Message: "'email' is invalid"}
 	}
 
 	return nil
 }
 
+// Update changes name and email, leaving the customer untouched when either
+// is invalid.
 func (c *Customer) Update(name string, email string) error {
+	email = NormalizeEmail(email)
+	updated := *c
+	updated.Name = name
+	updated.Email = email
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
 	c.Name = name
 	c.Email = email
-	return c.Validate()
+	return nil
 }
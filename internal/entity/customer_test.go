@@ -30,7 +30,9 @@ func TestNewCustomer_ErrorDueToEmptyEmail(t *testing.T) {
 	expectedEmail := ""
 	customer, err := NewCustomer(expectedName, expectedEmail)
 
-	assert.NotNil(t, err)
+	var validation *ValidationError
+	assert.ErrorAs(t, err, &validation)
+	assert.Equal(t, "email", validation.Field)
 	assert.Equal(t, err.Error(), "'email' is invalid")
 	assert.Nil(t, customer)
 }
@@ -45,6 +47,21 @@ func TestNewCustomer_ErrorDueToInvalidEmail(t *testing.T) {
 	assert.Nil(t, customer)
 }
 
+func TestNewCustomer_NormalizeEmail(t *testing.T) {
+	customer, err := NewCustomer("Alex", "  AlexandreBrunoDias@Gmail.com ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "alexandrebrunodias@gmail.com", customer.Email)
+}
+
+func TestNewCustomer_ErrorDueToEmailWithDisplayName(t *testing.T) {
+	customer, err := NewCustomer("Alex", "Alex <alexandrebrunodias@gmail.com>")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, err.Error(), "'email' is invalid")
+	assert.Nil(t, customer)
+}
+
 func TestUpdateCustomer_UpdatedSuccessfully(t *testing.T) {
 	expectedName := "Xela"
 	expectedEmail := "erdnaxelaonurbsaid@giamg.moc"
@@ -67,3 +84,22 @@ func TestUpdateCustomer_ErrorDueToInvalidParam(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, err.Error(), "'name' should not be blank")
 }
+
+func TestUpdateCustomer_NormalizeEmail(t *testing.T) {
+	customer, _ := NewCustomer("Alex", "alexandrebrunodias@gmail.com")
+
+	err := customer.Update("Alex", "Alex@Example.com")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "alex@example.com", customer.Email)
+}
+
+func TestUpdateCustomer_KeepCustomerOnInvalidParam(t *testing.T) {
+	customer, _ := NewCustomer("Alex", "alexandrebrunodias@gmail.com")
+
+	err := customer.Update("Xela", "invalid_email")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "Alex", customer.Name)
+	assert.Equal(t, "alexandrebrunodias@gmail.com", customer.Email)
+}
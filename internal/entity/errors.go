@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ValidationError reports input breaking an entity invariant. Field names the
+// offending input when there is a single one.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// NotFoundError reports a lookup for an entity that doesn't exist.
+type NotFoundError struct {
+	Entity string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.Entity)
+}
+
+// ConflictError reports an operation the current state of an entity forbids,
+// such as committing a transaction twice. Code, when set, is a stable,
+// machine-readable reason.
+type ConflictError struct {
+	Code    string
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// UnprocessableError reports a well-formed request the business rules refuse.
+// Code is a stable, machine-readable reason.
+type UnprocessableError struct {
+	Code    string
+	Message string
+}
+
+func (e *UnprocessableError) Error() string {
+	return e.Message
+}
+
+// InsufficientFundsError reports a debit beyond the balance plus the
+// OverdraftLimit of the account.
+type InsufficientFundsError struct {
+	CustomerID     uuid.UUID
+	Balance        decimal.Decimal
+	OverdraftLimit decimal.Decimal
+	Amount         decimal.Decimal
+}
+
+func (e *InsufficientFundsError) Error() string {
+	if e.OverdraftLimit.IsPositive() {
+		return fmt.Sprintf("customer %s has insufficient funds | balance: %s - overdraft limit: %s - debit amount: %s",
+			e.CustomerID, e.Balance.String(), e.OverdraftLimit.String(), e.Amount.String())
+	}
+	return fmt.Sprintf("customer %s has insufficient funds | balance: %s - debit amount: %s",
+		e.CustomerID, e.Balance.String(), e.Amount.String())
+}
+
+// TransactionFailedError reports a transaction the accounts refused to
+// commit. Transaction is left failed, with the reason, so it can be stored
+// apart from the work that was rolled back.
+type TransactionFailedError struct {
+	Transaction *Transaction
+	Err         error
+}
+
+func (e *TransactionFailedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransactionFailedError) Unwrap() error {
+	return e.Err
+}
+
+// LimitExceededError reports a transfer breaking one of the TransferLimits of
+// its source account. Reached is what the limit would have come to with the
+// transfer: its amount, the total of the day or the count of the day.
+type LimitExceededError struct {
+	Limit   string
+	Max     decimal.Decimal
+	Reached decimal.Decimal
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("transfer exceeds the %s limit | limit: %s - with transfer: %s",
+		e.Limit, e.Max.String(), e.Reached.String())
+}
+
+var ErrEmailAlreadyRegistered = &ConflictError{
+	Code:    "email_already_registered",
+	Message: "'email' is already registered to another customer",
+}
+
+var (
+	ErrCustomerNotFound    = &NotFoundError{Entity: "customer"}
+	ErrAccountNotFound     = &NotFoundError{Entity: "account"}
+	ErrTransactionNotFound = &NotFoundError{Entity: "transaction"}
+)
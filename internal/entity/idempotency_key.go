@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyKey remembers the outcome of a request so a client retry with
+// the same key replays it instead of executing it twice. Successful outcomes
+// and transfers the accounts refused are stored; any other failure rolls the
+// key back with it.
+type IdempotencyKey struct {
+	Key         string
+	Fingerprint string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func NewIdempotencyKey(key string, fingerprint string, response []byte, ttl time.Duration) (*IdempotencyKey, error) {
+	now := time.Now().UTC()
+	idempotencyKey := &IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Response:    response,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	if err := idempotencyKey.Validate(); err != nil {
+		return nil, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (k *IdempotencyKey) Validate() error {
+	if strings.TrimSpace(k.Key) == "" {
+		return &ValidationError{Field: "key", Message: "'key' should not be blank"}
+	}
+	if len(k.Key) > MaxIdempotencyKeyLength {
+		return &ValidationError{Field: "key", Message: "'key' should not be longer than 255 characters"}
+	}
+	if k.Fingerprint == "" {
+		return &ValidationError{Field: "fingerprint", Message: "'fingerprint' should not be blank"}
+	}
+	return nil
+}
+
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+func (k *IdempotencyKey) Matches(fingerprint string) bool {
+	return k.Fingerprint == fingerprint
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewIdempotencyKey_CreateSuccessfully(t *testing.T) {
+	expectedKey := "6f1c0f5e-retry"
+	expectedFingerprint := "fingerprint"
+	expectedResponse := []byte(`{"id":"123"}`)
+
+	key, err := NewIdempotencyKey(expectedKey, expectedFingerprint, expectedResponse, time.Hour)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedKey, key.Key)
+	assert.Equal(t, expectedResponse, key.Response)
+	assert.Equal(t, time.Hour, key.ExpiresAt.Sub(key.CreatedAt))
+	assert.True(t, key.Matches(expectedFingerprint))
+	assert.False(t, key.Matches("another fingerprint"))
+}
+
+func TestNewIdempotencyKey_FailDueToBlankKey(t *testing.T) {
+	key, err := NewIdempotencyKey(" ", "fingerprint", nil, time.Hour)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "'key' should not be blank", err.Error())
+	assert.Nil(t, key)
+}
+
+func TestNewIdempotencyKey_FailDueToTooLongKey(t *testing.T) {
+	key, err := NewIdempotencyKey(strings.Repeat("k", MaxIdempotencyKeyLength+1), "fingerprint", nil, time.Hour)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "'key' should not be longer than 255 characters", err.Error())
+	assert.Nil(t, key)
+}
+
+func TestIdempotencyKey_IsExpired(t *testing.T) {
+	key, _ := NewIdempotencyKey("key", "fingerprint", nil, time.Hour)
+
+	assert.False(t, key.IsExpired(key.CreatedAt))
+	assert.True(t, key.IsExpired(key.ExpiresAt))
+}
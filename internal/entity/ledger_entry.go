@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type EntryDirection string
+
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+// LedgerEntry is one side of a double-entry posting. Every transaction
+// produces a debit on its source account and a matching credit on its
+// destination account, each in the currency of its account.
+type LedgerEntry struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Direction     EntryDirection
+	Amount        decimal.Decimal
+	CreatedAt     time.Time
+}
+
+func newLedgerEntry(
+	transaction *Transaction,
+	account *Account,
+	direction EntryDirection,
+	amount decimal.Decimal,
+) *LedgerEntry {
+	return &LedgerEntry{
+		ID:            uuid.New(),
+		TransactionID: transaction.ID,
+		AccountID:     account.ID,
+		Direction:     direction,
+		Amount:        amount,
+		CreatedAt:     transaction.CreatedAt,
+	}
+}
+
+// SignedAmount is the effect of the entry on the account balance.
+func (e *LedgerEntry) SignedAmount() decimal.Decimal {
+	if e.Direction == Debit {
+		return e.Amount.Neg()
+	}
+	return e.Amount
+}
+
+// LedgerBalance compares the balance stored on an account with the one
+// recomputed from its ledger entries.
+type LedgerBalance struct {
+	AccountID     uuid.UUID
+	Balance       decimal.Decimal
+	LedgerBalance decimal.Decimal
+}
+
+func (b *LedgerBalance) Drift() decimal.Decimal {
+	return b.Balance.Sub(b.LedgerBalance)
+}
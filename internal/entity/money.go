@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// Currency is an ISO 4217 alphabetic currency code.
+type Currency string
+
+const (
+	BRL Currency = "BRL"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+	USD Currency = "USD"
+
+	// DefaultCurrency is the currency of accounts opened before accounts had
+	// one, and of new accounts opened without one.
+	DefaultCurrency = BRL
+)
+
+// minorUnits lists the supported currencies with the number of decimal
+// places ISO 4217 gives their minor unit.
+var minorUnits = map[Currency]int32{
+	"ARS": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"PYG": 0,
+	"USD": 2,
+	"UYU": 2,
+}
+
+// ParseCurrency accepts a supported ISO 4217 code in any case.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !currency.IsValid() {
+		return "", unsupportedCurrency(code)
+	}
+	return currency, nil
+}
+
+func unsupportedCurrency(code string) error {
+	return &ValidationError{
+		Field:   "currency",
+		Message: fmt.Sprintf("'currency' %q is not a supported ISO 4217 code", code),
+	}
+}
+
+func (c Currency) IsValid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+// MinorUnits is the number of decimal places amounts in c may have.
+func (c Currency) MinorUnits() int32 {
+	return minorUnits[c]
+}
+
+// Money is an amount in a currency, never more precise than the currency's
+// minor unit.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency Currency
+}
+
+func NewMoney(amount decimal.Decimal, currency Currency) (Money, error) {
+	if !currency.IsValid() {
+		return Money{}, unsupportedCurrency(string(currency))
+	}
+	if !amount.Equal(amount.Truncate(currency.MinorUnits())) {
+		return Money{}, &ValidationError{
+			Field: "amount",
+			Message: fmt.Sprintf(
+				"'amount' %s has more than the %d decimal places %s allows",
+				amount.String(), currency.MinorUnits(), currency,
+			),
+		}
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Convert prices m in currency at rate, the amount of currency one unit of
+// m's currency is worth, rounded to currency's minor unit by rounding.
+func (m Money) Convert(currency Currency, rate decimal.Decimal, rounding RoundingMode) Money {
+	return Money{Amount: rounding.Round(m.Amount.Mul(rate), currency.MinorUnits()), Currency: currency}
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount.IsPositive()
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Amount.StringFixed(m.Currency.MinorUnits()), m.Currency)
+}
+
+// RoundingMode decides how amounts are brought to the minor unit of their
+// currency after a conversion.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero.
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds halves to the nearest even digit, so rounding
+	// errors don't pile up in one direction.
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown truncates towards zero.
+	RoundDown RoundingMode = "down"
+	// RoundUp rounds away from zero.
+	RoundUp RoundingMode = "up"
+
+	DefaultRoundingMode = RoundHalfEven
+)
+
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	rounding := RoundingMode(strings.ToLower(strings.TrimSpace(mode)))
+	switch rounding {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+		return rounding, nil
+	}
+	return "", &ValidationError{
+		Field: "rounding",
+		Message: fmt.Sprintf(
+			"'rounding' must be %q, %q, %q or %q, got %q",
+			RoundHalfUp, RoundHalfEven, RoundDown, RoundUp, mode,
+		),
+	}
+}
+
+// Round rounds amount to places decimal places, falling back to
+// DefaultRoundingMode when r is empty.
+func (r RoundingMode) Round(amount decimal.Decimal, places int32) decimal.Decimal {
+	switch r {
+	case RoundHalfUp:
+		return amount.Round(places)
+	case RoundDown:
+		return amount.RoundDown(places)
+	case RoundUp:
+		return amount.RoundUp(places)
+	default:
+		return amount.RoundBank(places)
+	}
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseCurrency_AcceptAnyCase(t *testing.T) {
+	currency, err := ParseCurrency(" usd ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, USD, currency)
+}
+
+func TestParseCurrency_FailDueToUnsupportedCode(t *testing.T) {
+	currency, err := ParseCurrency("XYZ")
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "currency", validationError.Field)
+	assert.Equal(t, Currency(""), currency)
+}
+
+func TestNewMoney_EnforceMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency Currency
+		valid    bool
+	}{
+		{amount: "10.25", currency: BRL, valid: true},
+		{amount: "10.250", currency: BRL, valid: true},
+		{amount: "10.255", currency: BRL, valid: false},
+		{amount: "1000", currency: JPY, valid: true},
+		{amount: "1000.5", currency: JPY, valid: false},
+		{amount: "1.125", currency: "KWD", valid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.amount+" "+string(test.currency), func(t *testing.T) {
+			money, err := NewMoney(decimal.RequireFromString(test.amount), test.currency)
+
+			if test.valid {
+				assert.Nil(t, err)
+				assert.Equal(t, test.currency, money.Currency)
+				return
+			}
+			var validationError *ValidationError
+			assert.ErrorAs(t, err, &validationError)
+			assert.Equal(t, "amount", validationError.Field)
+		})
+	}
+}
+
+func TestMoney_ConvertRoundingToTargetMinorUnits(t *testing.T) {
+	money, _ := NewMoney(decimal.RequireFromString("100.00"), BRL)
+
+	dollars := money.Convert(USD, decimal.RequireFromString("0.19345"), RoundHalfEven)
+	yen := money.Convert(JPY, decimal.RequireFromString("28.456"), RoundHalfEven)
+
+	assert.Equal(t, "19.34 USD", dollars.String())
+	assert.Equal(t, "2846 JPY", yen.String())
+}
+
+func TestRoundingMode_Round(t *testing.T) {
+	tests := []struct {
+		rounding RoundingMode
+		amount   string
+		expected string
+	}{
+		{rounding: RoundHalfUp, amount: "19.345", expected: "19.35"},
+		{rounding: RoundHalfUp, amount: "-19.345", expected: "-19.35"},
+		{rounding: RoundHalfEven, amount: "19.345", expected: "19.34"},
+		{rounding: RoundHalfEven, amount: "19.355", expected: "19.36"},
+		{rounding: RoundDown, amount: "19.349", expected: "19.34"},
+		{rounding: RoundUp, amount: "19.341", expected: "19.35"},
+		{rounding: "", amount: "19.345", expected: "19.34"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.rounding)+" "+test.amount, func(t *testing.T) {
+			rounded := test.rounding.Round(decimal.RequireFromString(test.amount), 2)
+
+			assert.Equal(t, test.expected, rounded.String())
+		})
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	rounding, err := ParseRoundingMode(" HALF_UP ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, RoundHalfUp, rounding)
+
+	_, err = ParseRoundingMode("ceiling")
+
+	var validationError *ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "rounding", validationError.Field)
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type TransferDirection string
+
+const (
+	Incoming TransferDirection = "incoming"
+	Outgoing TransferDirection = "outgoing"
+)
+
+// StatementEntry is a transaction as seen from one of its accounts, along
+// with that account's balance right after the transaction.
+type StatementEntry struct {
+	Transaction    *Transaction
+	Direction      TransferDirection
+	RunningBalance decimal.Decimal
+}
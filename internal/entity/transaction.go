@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"time"
@@ -9,28 +10,113 @@ import (
 
 type Status string
 
+const (
+	Pending   Status = "pending"
+	Completed Status = "completed"
+	Failed    Status = "failed"
+	Reversed  Status = "reversed"
+)
+
+// Failure reasons a failed transaction is stored with.
+const (
+	FailureInsufficientFunds = "insufficient_funds"
+	FailureInvalidAmount     = "invalid_amount"
+	FailureRejected          = "rejected"
+)
+
+// transitions lists, for each status, the statuses a transaction may move to.
+var transitions = map[Status][]Status{
+	Pending:   {Completed, Failed},
+	Completed: {Reversed},
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Transaction debits Amount from FromAccount, in its currency, and credits
+// DestinationAmount to ToAccount, in its own. Both amounts are the same unless
+// the currencies differ, in which case FXRate records the price of one unit of
+// the FromAccount currency in the ToAccount currency. RefundedAmount is in the
+// FromAccount currency, like Amount, and RefundedDestinationAmount is what the
+// ToAccount gave back of DestinationAmount. FailureReason is the stable code
+// of why a failed transaction was refused.
 type Transaction struct {
-	ID          uuid.UUID
-	FromAccount *Account
-	ToAccount   *Account
-	Status      Status
-	Amount      decimal.Decimal
-	CreatedAt   time.Time
+	ID                        uuid.UUID
+	FromAccount               *Account
+	ToAccount                 *Account
+	Status                    Status
+	Amount                    decimal.Decimal
+	DestinationAmount         decimal.Decimal
+	FXRate                    *decimal.Decimal
+	RefundedAmount            decimal.Decimal
+	RefundedDestinationAmount decimal.Decimal
+	ReversalOf                *uuid.UUID
+	FailureReason             string
+	CreatedAt                 time.Time
+}
+
+// fxRatePlaces is the precision rates are stored with.
+const fxRatePlaces = 12
+
+// Conversion prices a transfer between accounts in different currencies.
+type Conversion struct {
+	Rate     decimal.Decimal
+	Rounding RoundingMode
+}
+
+// NewTransaction needs conversion exactly when the accounts are in different
+// currencies, and rejects the transfer otherwise.
+func NewTransaction(
+	fromAccount *Account,
+	toAccount *Account,
+	amount decimal.Decimal,
+	conversion *Conversion,
+) (*Transaction, error) {
+	if conversion == nil || fromAccount == nil || toAccount == nil {
+		var fxRate *decimal.Decimal
+		if conversion != nil {
+			fxRate = &conversion.Rate
+		}
+		return newTransaction(fromAccount, toAccount, amount, amount, fxRate)
+	}
+
+	rate := conversion.Rate.Round(fxRatePlaces)
+	source := Money{Amount: amount, Currency: fromAccount.Currency}
+	destination := source.Convert(toAccount.Currency, rate, conversion.Rounding)
+	return newTransaction(fromAccount, toAccount, amount, destination.Amount, &rate)
 }
 
-func NewTransaction(fromAccount *Account, toAccount *Account, amount decimal.Decimal) (*Transaction, error) {
+func newTransaction(
+	fromAccount *Account,
+	toAccount *Account,
+	amount decimal.Decimal,
+	destinationAmount decimal.Decimal,
+	fxRate *decimal.Decimal,
+) (*Transaction, error) {
 	transaction := &Transaction{
-		ID:          uuid.New(),
-		FromAccount: fromAccount,
-		ToAccount:   toAccount,
-		Amount:      amount,
-		CreatedAt:   time.Now().UTC(),
+		ID:                uuid.New(),
+		FromAccount:       fromAccount,
+		ToAccount:         toAccount,
+		Status:            Pending,
+		Amount:            amount,
+		DestinationAmount: destinationAmount,
+		FXRate:            fxRate,
+		CreatedAt:         time.Now().UTC(),
 	}
 	err := transaction.Validate()
 	if err != nil {
 		return nil, err
 	}
 	err = transaction.Commit()
+	if err != nil && transaction.Status == Failed {
+		return nil, &TransactionFailedError{Transaction: transaction, Err: err}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -38,22 +124,170 @@ func NewTransaction(fromAccount *Account, toAccount *Account, amount decimal.Dec
 	return transaction, nil
 }
 
+// NewReversal creates a compensating transaction moving amount back from the
+// original destination to the original source, and records it as refunded on
+// the original. Partial reversals are allowed up to the remaining amount.
+func NewReversal(original *Transaction, amount decimal.Decimal) (*Transaction, error) {
+	if original.ReversalOf != nil {
+		return nil, &ConflictError{Message: "a reversal cannot be reversed"}
+	}
+	if original.Status != Completed && original.Status != Reversed {
+		return nil, &ConflictError{
+			Message: fmt.Sprintf("transaction %s cannot be reversed while '%s'", original.ID, original.Status),
+		}
+	}
+	if original.RemainingAmount().IsZero() {
+		return nil, &ConflictError{
+			Code:    "already_fully_reversed",
+			Message: fmt.Sprintf("transaction %s is already fully reversed", original.ID),
+		}
+	}
+	if amount.GreaterThan(original.RemainingAmount()) {
+		return nil, &UnprocessableError{
+			Code: "amount_exceeds_remaining",
+			Message: fmt.Sprintf(
+				"reversal amount %s exceeds the remaining amount %s",
+				amount.String(), original.RemainingAmount().String(),
+			),
+		}
+	}
+
+	reversal, err := newReversalTransaction(original, amount)
+	if err != nil {
+		return nil, err
+	}
+	reversal.ReversalOf = &original.ID
+
+	original.RefundedAmount = original.RefundedAmount.Add(amount)
+	original.RefundedDestinationAmount = original.RefundedDestinationAmount.Add(reversal.Amount)
+	if original.Status == Completed {
+		if err = original.TransitionTo(Reversed); err != nil {
+			return nil, err
+		}
+	}
+
+	return reversal, nil
+}
+
+// newReversalTransaction moves amount, in the original source currency, back
+// to the original source. Between currencies, the destination gives back its
+// share of what it received, rounded half to even, and the reversal emptying
+// the remaining amount gives back whatever is left of it. However it was
+// split, the destination returns every cent it received and no more, whatever
+// the rate has become since.
+func newReversalTransaction(original *Transaction, amount decimal.Decimal) (*Transaction, error) {
+	if original.FXRate == nil {
+		return NewTransaction(original.ToAccount, original.FromAccount, amount, nil)
+	}
+
+	returned := original.DestinationAmount.Sub(original.RefundedDestinationAmount)
+	if !amount.Equal(original.RemainingAmount()) {
+		returned = decimal.Min(returned, DefaultRoundingMode.Round(
+			amount.Mul(original.DestinationAmount).Div(original.Amount),
+			original.ToAccount.Currency.MinorUnits(),
+		))
+	}
+	rate := original.Amount.DivRound(original.DestinationAmount, fxRatePlaces)
+	return newTransaction(original.ToAccount, original.FromAccount, returned, amount, &rate)
+}
+
+// RemainingAmount is the part of the transaction that has not been reversed.
+func (t *Transaction) RemainingAmount() decimal.Decimal {
+	return t.Amount.Sub(t.RefundedAmount)
+}
+
+// Commit moves the amount between the accounts, completing a pending
+// transaction or marking it as failed when the accounts reject it.
 func (t *Transaction) Commit() error {
+	if t.Status != Pending {
+		return &ConflictError{
+			Message: fmt.Sprintf("transaction %s cannot be committed while '%s'", t.ID, t.Status),
+		}
+	}
 	if err := t.FromAccount.Debit(t.Amount); err != nil {
-		return err
+		return t.fail(err)
 	}
-	if err := t.ToAccount.Credit(t.Amount); err != nil {
-		return err
+	if err := t.ToAccount.Credit(t.DestinationAmount); err != nil {
+		return t.fail(err)
+	}
+	return t.TransitionTo(Completed)
+}
+
+func (t *Transaction) fail(err error) error {
+	if transitionErr := t.TransitionTo(Failed); transitionErr != nil {
+		return transitionErr
+	}
+	t.FailureReason = failureReason(err)
+	return err
+}
+
+// failureReason reduces the error refusing a transaction to a stable code,
+// as its message may carry balances and limits that must not be stored.
+func failureReason(err error) string {
+	var insufficientFunds *InsufficientFundsError
+	var validation *ValidationError
+	if errors.As(err, &insufficientFunds) {
+		return FailureInsufficientFunds
+	}
+	if errors.As(err, &validation) {
+		return FailureInvalidAmount
 	}
+	return FailureRejected
+}
+
+func (t *Transaction) TransitionTo(status Status) error {
+	if !t.Status.CanTransitionTo(status) {
+		return &ConflictError{
+			Message: fmt.Sprintf("transaction %s cannot move from '%s' to '%s'", t.ID, t.Status, status),
+		}
+	}
+	t.Status = status
 	return nil
 }
 
+// LedgerEntries returns the debit and credit postings of the transaction.
+func (t *Transaction) LedgerEntries() []*LedgerEntry {
+	return []*LedgerEntry{
+		newLedgerEntry(t, t.FromAccount, Debit, t.Amount),
+		newLedgerEntry(t, t.ToAccount, Credit, t.DestinationAmount),
+	}
+}
+
 func (t *Transaction) Validate() error {
 	if t.FromAccount == nil || t.ToAccount == nil {
-		return errors.New("neither 'FromAccount' nor 'ToAccount' can be nil")
+		return &ValidationError{Message: "neither 'FromAccount' nor 'ToAccount' can be nil"}
 	}
 	if t.Amount.IsNegative() || t.Amount.IsZero() {
-		return errors.New("'amount' must be a non zero positive number")
+		return &ValidationError{Field: "amount", Message: "'amount' must be a non zero positive number"}
+	}
+	if _, err := NewMoney(t.Amount, t.FromAccount.Currency); err != nil {
+		return err
+	}
+
+	sameCurrency := t.FromAccount.Currency == t.ToAccount.Currency
+	if !sameCurrency && t.FXRate == nil {
+		return &UnprocessableError{
+			Code: "currency_mismatch",
+			Message: fmt.Sprintf(
+				"transfers from %s to %s need an FX rate",
+				t.FromAccount.Currency, t.ToAccount.Currency,
+			),
+		}
+	}
+	if sameCurrency && t.FXRate != nil {
+		return &ValidationError{Field: "fx_rate", Message: "'fx_rate' is only allowed between different currencies"}
+	}
+	if t.FXRate != nil && !t.FXRate.IsPositive() {
+		return &ValidationError{Field: "fx_rate", Message: "'fx_rate' must be a non zero positive number"}
+	}
+	if !t.DestinationAmount.IsPositive() {
+		return &ValidationError{
+			Field:   "amount",
+			Message: fmt.Sprintf("'amount' is worth nothing once converted to %s", t.ToAccount.Currency),
+		}
+	}
+	if _, err := NewMoney(t.DestinationAmount, t.ToAccount.Currency); err != nil {
+		return err
 	}
 	return nil
 }
@@ -23,7 +23,7 @@ func (s *TransactionTestSuite) TestNewTransaction_CreateSuccessfully() {
 	expectedToAccountFinalBalance := expectedToAccountInitialBalance.Add(expectedAmount)
 	_ = s.AccountTo.Credit(expectedToAccountInitialBalance)
 
-	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, expectedAmount)
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, expectedAmount, nil)
 
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), transaction)
@@ -31,12 +31,13 @@ func (s *TransactionTestSuite) TestNewTransaction_CreateSuccessfully() {
 	assert.Equal(s.T(), expectedFromAccountFinalBalance, transaction.FromAccount.Balance)
 	assert.Equal(s.T(), expectedToAccountFinalBalance, transaction.ToAccount.Balance)
 	assert.NotNil(s.T(), transaction.CreatedAt)
+	assert.Equal(s.T(), Completed, transaction.Status)
 }
 
 func (s *TransactionTestSuite) TestNewTransaction_FailDueToNilFromAccount() {
 	expectedErrorMessage := "neither 'FromAccount' nor 'ToAccount' can be nil"
 
-	transaction, err := NewTransaction(nil, s.AccountTo, decimal.NewFromInt(100))
+	transaction, err := NewTransaction(nil, s.AccountTo, decimal.NewFromInt(100), nil)
 
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), expectedErrorMessage, err.Error())
@@ -46,7 +47,7 @@ func (s *TransactionTestSuite) TestNewTransaction_FailDueToNilFromAccount() {
 func (s *TransactionTestSuite) TestNewTransaction_FailDueToNilToAccount() {
 	expectedErrorMessage := "neither 'FromAccount' nor 'ToAccount' can be nil"
 
-	transaction, err := NewTransaction(nil, s.AccountFrom, decimal.NewFromInt(100))
+	transaction, err := NewTransaction(nil, s.AccountFrom, decimal.NewFromInt(100), nil)
 
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), expectedErrorMessage, err.Error())
@@ -56,7 +57,7 @@ func (s *TransactionTestSuite) TestNewTransaction_FailDueToNilToAccount() {
 func (s *TransactionTestSuite) TestNewTransaction_FailDueToNegativeAmount() {
 	expectedErrorMessage := "'amount' must be a non zero positive number"
 
-	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(-100))
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(-100), nil)
 
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), expectedErrorMessage, err.Error())
@@ -66,7 +67,7 @@ func (s *TransactionTestSuite) TestNewTransaction_FailDueToNegativeAmount() {
 func (s *TransactionTestSuite) TestNewTransaction_FailDueToNegativeZero() {
 	expectedErrorMessage := "'amount' must be a non zero positive number"
 
-	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.Zero)
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.Zero, nil)
 
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), expectedErrorMessage, err.Error())
@@ -80,13 +81,302 @@ func (s *TransactionTestSuite) TestNewTransaction_FailDueToErrorDebitingAccount(
 		s.CustomerFrom.ID, expectedAmount.String(),
 	)
 
-	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, expectedAmount)
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, expectedAmount, nil)
 
 	assert.NotNil(s.T(), err)
 	assert.Equal(s.T(), expectedErrorMessage, err.Error())
 	assert.Nil(s.T(), transaction)
 }
 
+func (s *TransactionTestSuite) TestNewTransaction_FailDueToAmountBeyondMinorUnits() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.RequireFromString("10.005"), nil)
+
+	var validationError *ValidationError
+	assert.ErrorAs(s.T(), err, &validationError)
+	assert.Equal(s.T(), "amount", validationError.Field)
+	assert.Nil(s.T(), transaction)
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+}
+
+func (s *TransactionTestSuite) TestNewTransaction_FailDueToCurrencyMismatchWithoutFXRate() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	toAccount, _ := NewAccount(s.CustomerTo, USD)
+
+	transaction, err := NewTransaction(s.AccountFrom, toAccount, decimal.NewFromInt(100), nil)
+
+	var unprocessable *UnprocessableError
+	assert.ErrorAs(s.T(), err, &unprocessable)
+	assert.Equal(s.T(), "currency_mismatch", unprocessable.Code)
+	assert.Equal(s.T(), "transfers from BRL to USD need an FX rate", err.Error())
+	assert.Nil(s.T(), transaction)
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+}
+
+func (s *TransactionTestSuite) TestNewTransaction_FailDueToFXRateWithinSameCurrency() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	conversion := &Conversion{Rate: decimal.RequireFromString("1.5")}
+
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), conversion)
+
+	var validationError *ValidationError
+	assert.ErrorAs(s.T(), err, &validationError)
+	assert.Equal(s.T(), "fx_rate", validationError.Field)
+	assert.Nil(s.T(), transaction)
+}
+
+func (s *TransactionTestSuite) TestNewTransaction_ConvertBetweenCurrenciesWithFXRate() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	toAccount, _ := NewAccount(s.CustomerTo, USD)
+	conversion := &Conversion{Rate: decimal.RequireFromString("0.19345"), Rounding: RoundHalfUp}
+
+	transaction, err := NewTransaction(s.AccountFrom, toAccount, decimal.NewFromInt(100), conversion)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), Completed, transaction.Status)
+	assert.Equal(s.T(), "100", transaction.Amount.String())
+	assert.Equal(s.T(), "19.35", transaction.DestinationAmount.String())
+	assert.Equal(s.T(), "0.19345", transaction.FXRate.String())
+	assert.Equal(s.T(), "100", s.AccountFrom.Balance.String())
+	assert.Equal(s.T(), "19.35", toAccount.Balance.String())
+
+	entries := transaction.LedgerEntries()
+	assert.Equal(s.T(), "100", entries[0].Amount.String())
+	assert.Equal(s.T(), "19.35", entries[1].Amount.String())
+}
+
+func (s *TransactionTestSuite) TestNewTransaction_FailDueToAmountWorthNothingOnceConverted() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	toAccount, _ := NewAccount(s.CustomerTo, USD)
+	conversion := &Conversion{Rate: decimal.RequireFromString("0.19"), Rounding: RoundDown}
+
+	transaction, err := NewTransaction(s.AccountFrom, toAccount, decimal.RequireFromString("0.05"), conversion)
+
+	var validationError *ValidationError
+	assert.ErrorAs(s.T(), err, &validationError)
+	assert.Equal(s.T(), "amount", validationError.Field)
+	assert.Nil(s.T(), transaction)
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+}
+
+func (s *TransactionTestSuite) TestNewReversal_GiveBackConvertedShareBetweenCurrencies() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	toAccount, _ := NewAccount(s.CustomerTo, USD)
+	conversion := &Conversion{Rate: decimal.RequireFromString("0.19345"), Rounding: RoundHalfUp}
+	original, _ := NewTransaction(s.AccountFrom, toAccount, decimal.NewFromInt(100), conversion)
+
+	partial, err := NewReversal(original, decimal.NewFromInt(40))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "7.74", partial.Amount.String())
+	assert.Equal(s.T(), "40", partial.DestinationAmount.String())
+	assert.Equal(s.T(), "140", s.AccountFrom.Balance.String())
+	assert.Equal(s.T(), "11.61", toAccount.Balance.String())
+
+	rest, err := NewReversal(original, original.RemainingAmount())
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "11.61", rest.Amount.String())
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+	assert.True(s.T(), toAccount.Balance.IsZero())
+}
+
+func (s *TransactionTestSuite) TestNewReversal_GiveBackExactlyTheDestinationAmountOverPartialReversals() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	toAccount, _ := NewAccount(s.CustomerTo, USD)
+	conversion := &Conversion{Rate: decimal.RequireFromString("0.19"), Rounding: RoundHalfEven}
+	original, _ := NewTransaction(s.AccountFrom, toAccount, decimal.NewFromInt(1), conversion)
+	s.Require().Equal("0.19", original.DestinationAmount.String())
+
+	first, err := NewReversal(original, decimal.RequireFromString("0.5"))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "0.1", first.Amount.String())
+	assert.Equal(s.T(), "0.1", original.RefundedDestinationAmount.String())
+
+	second, err := NewReversal(original, decimal.RequireFromString("0.5"))
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "0.09", second.Amount.String())
+	assert.Equal(s.T(), "0.19", original.RefundedDestinationAmount.String())
+	assert.True(s.T(), original.RemainingAmount().IsZero())
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+	assert.True(s.T(), toAccount.Balance.IsZero())
+}
+
+func (s *TransactionTestSuite) TestCommit_MarkFailedDueToInsufficientFunds() {
+	transaction := &Transaction{
+		FromAccount: s.AccountFrom,
+		ToAccount:   s.AccountTo,
+		Status:      Pending,
+		Amount:      decimal.NewFromInt(100),
+	}
+
+	err := transaction.Commit()
+
+	var insufficientFunds *InsufficientFundsError
+	assert.ErrorAs(s.T(), err, &insufficientFunds)
+	assert.Equal(s.T(), Failed, transaction.Status)
+	assert.Equal(s.T(), FailureInsufficientFunds, transaction.FailureReason)
+}
+
+func (s *TransactionTestSuite) TestNewTransaction_ReturnFailedTransactionDueToInsufficientFunds() {
+	transaction, err := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+
+	var failed *TransactionFailedError
+	assert.ErrorAs(s.T(), err, &failed)
+	assert.Nil(s.T(), transaction)
+	assert.Equal(s.T(), Failed, failed.Transaction.Status)
+	assert.Equal(s.T(), FailureInsufficientFunds, failed.Transaction.FailureReason)
+	assert.True(s.T(), s.AccountFrom.Balance.IsZero())
+}
+
+func (s *TransactionTestSuite) TestCommit_FailDueToTransactionNotPending() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	transaction, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+	expectedErrorMessage := fmt.Sprintf("transaction %s cannot be committed while 'completed'", transaction.ID)
+
+	err := transaction.Commit()
+
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), expectedErrorMessage, err.Error())
+	assert.Equal(s.T(), "100", s.AccountFrom.Balance.String())
+}
+
+func (s *TransactionTestSuite) TestTransitionTo_FollowAllowedTransitions() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	transaction, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+
+	err := transaction.TransitionTo(Reversed)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), Reversed, transaction.Status)
+}
+
+func (s *TransactionTestSuite) TestTransitionTo_FailDueToNotAllowedTransition() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	transaction, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+	expectedErrorMessage := fmt.Sprintf("transaction %s cannot move from 'completed' to 'pending'", transaction.ID)
+
+	err := transaction.TransitionTo(Pending)
+
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), expectedErrorMessage, err.Error())
+	assert.Equal(s.T(), Completed, transaction.Status)
+}
+
+func (s *TransactionTestSuite) TestNewReversal_ReverseFullAmount() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	original, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+
+	reversal, err := NewReversal(original, original.Amount)
+
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), s.AccountTo, reversal.FromAccount)
+	assert.Equal(s.T(), s.AccountFrom, reversal.ToAccount)
+	assert.Equal(s.T(), Completed, reversal.Status)
+	assert.Equal(s.T(), original.ID, *reversal.ReversalOf)
+	assert.Equal(s.T(), Reversed, original.Status)
+	assert.True(s.T(), original.RemainingAmount().IsZero())
+	assert.Equal(s.T(), "200", s.AccountFrom.Balance.String())
+	assert.Equal(s.T(), "0", s.AccountTo.Balance.String())
+}
+
+func (s *TransactionTestSuite) TestNewReversal_ReversePartiallyUpToRemainingAmount() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	original, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+
+	_, err := NewReversal(original, decimal.NewFromInt(30))
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), Reversed, original.Status)
+	assert.Equal(s.T(), "70", original.RemainingAmount().String())
+
+	_, err = NewReversal(original, decimal.NewFromInt(70))
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), original.RemainingAmount().IsZero())
+
+	reversal, err := NewReversal(original, decimal.NewFromInt(1))
+	var conflict *ConflictError
+	assert.ErrorAs(s.T(), err, &conflict)
+	assert.Nil(s.T(), reversal)
+}
+
+func (s *TransactionTestSuite) TestNewReversal_FailDueToAlreadyFullyReversed() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	original, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+	_, _ = NewReversal(original, decimal.NewFromInt(100))
+
+	reversal, err := NewReversal(original, original.RemainingAmount())
+
+	var conflict *ConflictError
+	assert.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), "already_fully_reversed", conflict.Code)
+	assert.Equal(s.T(), fmt.Sprintf("transaction %s is already fully reversed", original.ID), err.Error())
+	assert.Nil(s.T(), reversal)
+}
+
+func (s *TransactionTestSuite) TestNewReversal_FailDueToReversingAReversal() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	original, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+	reversal, _ := NewReversal(original, decimal.NewFromInt(50))
+
+	reversalOfReversal, err := NewReversal(reversal, decimal.NewFromInt(10))
+
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), "a reversal cannot be reversed", err.Error())
+	assert.Nil(s.T(), reversalOfReversal)
+}
+
+func (s *TransactionTestSuite) TestNewReversal_FailDueToNotCompletedTransaction() {
+	original := &Transaction{
+		FromAccount: s.AccountFrom,
+		ToAccount:   s.AccountTo,
+		Status:      Failed,
+		Amount:      decimal.NewFromInt(100),
+	}
+	expectedErrorMessage := fmt.Sprintf("transaction %s cannot be reversed while 'failed'", original.ID)
+
+	reversal, err := NewReversal(original, decimal.NewFromInt(100))
+
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), expectedErrorMessage, err.Error())
+	assert.Nil(s.T(), reversal)
+}
+
+func (s *TransactionTestSuite) TestNewReversal_FailDueToInsufficientFundsOnDestination() {
+	_ = s.AccountFrom.Credit(decimal.NewFromInt(200))
+	original, _ := NewTransaction(s.AccountFrom, s.AccountTo, decimal.NewFromInt(100), nil)
+	_ = s.AccountTo.Debit(decimal.NewFromInt(80))
+
+	reversal, err := NewReversal(original, decimal.NewFromInt(100))
+
+	assert.NotNil(s.T(), err)
+	assert.Nil(s.T(), reversal)
+	assert.Equal(s.T(), Completed, original.Status)
+	assert.True(s.T(), original.RefundedAmount.IsZero())
+}
+
+func (s *TransactionTestSuite) TestLedgerEntries_DebitFromAndCreditTo() {
+	expectedAmount := decimal.NewFromInt(100)
+	_ = s.AccountFrom.Credit(expectedAmount)
+
+	transaction, _ := NewTransaction(s.AccountFrom, s.AccountTo, expectedAmount, nil)
+	entries := transaction.LedgerEntries()
+
+	assert.Len(s.T(), entries, 2)
+	assert.Equal(s.T(), s.AccountFrom.ID, entries[0].AccountID)
+	assert.Equal(s.T(), Debit, entries[0].Direction)
+	assert.Equal(s.T(), expectedAmount.Neg(), entries[0].SignedAmount())
+	assert.Equal(s.T(), s.AccountTo.ID, entries[1].AccountID)
+	assert.Equal(s.T(), Credit, entries[1].Direction)
+	assert.Equal(s.T(), expectedAmount, entries[1].SignedAmount())
+	for _, entry := range entries {
+		assert.Equal(s.T(), transaction.ID, entry.TransactionID)
+		assert.Equal(s.T(), expectedAmount, entry.Amount)
+	}
+}
+
 type TransactionTestSuite struct {
 	suite.Suite
 	CustomerFrom *Customer
@@ -99,11 +389,11 @@ func (s *TransactionTestSuite) SetupTest() {
 	var err error
 	s.CustomerFrom, err = NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	s.Require().Nil(err)
-	s.AccountFrom, err = NewAccount(s.CustomerFrom)
+	s.AccountFrom, err = NewAccount(s.CustomerFrom, BRL)
 	s.Require().Nil(err)
 
 	s.CustomerTo, err = NewCustomer("alex", "alexandrebrunodias@gmail.com")
 	s.Require().Nil(err)
-	s.AccountTo, err = NewAccount(s.CustomerTo)
+	s.AccountTo, err = NewAccount(s.CustomerTo, BRL)
 	s.Require().Nil(err)
 }
@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Names of the transfer limits, as reported by LimitExceededError.
+const (
+	MaxTransferAmount = "max_transfer_amount"
+	MaxDailyAmount    = "max_daily_amount"
+	MaxDailyCount     = "max_daily_count"
+)
+
+// maxDailyCountLimit is the largest count the accounts table can store.
+const maxDailyCountLimit = math.MaxInt32
+
+// TransferLimits caps what an account may send, in its currency. A nil limit
+// doesn't apply; a zero one blocks every transfer.
+type TransferLimits struct {
+	MaxTransferAmount *decimal.Decimal
+	MaxDailyAmount    *decimal.Decimal
+	MaxDailyCount     *int64
+}
+
+// TransferTotals sums the transfers an account sent over a period.
+type TransferTotals struct {
+	Count  int64
+	Amount decimal.Decimal
+}
+
+// HasDailyLimits tells whether checking the limits needs the totals of the
+// day.
+func (l TransferLimits) HasDailyLimits() bool {
+	return l.MaxDailyAmount != nil || l.MaxDailyCount != nil
+}
+
+// Equal compares the limits by value.
+func (l TransferLimits) Equal(other TransferLimits) bool {
+	return equalDecimals(l.MaxTransferAmount, other.MaxTransferAmount) &&
+		equalDecimals(l.MaxDailyAmount, other.MaxDailyAmount) &&
+		(l.MaxDailyCount == nil) == (other.MaxDailyCount == nil) &&
+		(l.MaxDailyCount == nil || *l.MaxDailyCount == *other.MaxDailyCount)
+}
+
+// String lists every limit, e.g. "max_transfer_amount=1000
+// max_daily_amount=none max_daily_count=10", the form audit entries keep.
+func (l TransferLimits) String() string {
+	count := "none"
+	if l.MaxDailyCount != nil {
+		count = strconv.FormatInt(*l.MaxDailyCount, 10)
+	}
+	return strings.Join([]string{
+		MaxTransferAmount + "=" + formatLimit(l.MaxTransferAmount),
+		MaxDailyAmount + "=" + formatLimit(l.MaxDailyAmount),
+		MaxDailyCount + "=" + count,
+	}, " ")
+}
+
+// Validate checks that every limit is a non-negative amount currency can
+// represent.
+func (l TransferLimits) Validate(currency Currency) error {
+	for _, limit := range []struct {
+		name   string
+		amount *decimal.Decimal
+	}{
+		{MaxTransferAmount, l.MaxTransferAmount},
+		{MaxDailyAmount, l.MaxDailyAmount},
+	} {
+		if limit.amount == nil {
+			continue
+		}
+		if limit.amount.IsNegative() {
+			return &ValidationError{Field: limit.name, Message: fmt.Sprintf("'%s' must not be negative", limit.name)}
+		}
+		if !limit.amount.Equal(limit.amount.Truncate(currency.MinorUnits())) {
+			return &ValidationError{
+				Field: limit.name,
+				Message: fmt.Sprintf(
+					"'%s' %s has more than the %d decimal places %s allows",
+					limit.name, limit.amount.String(), currency.MinorUnits(), currency,
+				),
+			}
+		}
+	}
+	if l.MaxDailyCount != nil && *l.MaxDailyCount < 0 {
+		return &ValidationError{Field: MaxDailyCount, Message: "'max_daily_count' must not be negative"}
+	}
+	if l.MaxDailyCount != nil && *l.MaxDailyCount > maxDailyCountLimit {
+		return &ValidationError{
+			Field:   MaxDailyCount,
+			Message: fmt.Sprintf("'max_daily_count' must not exceed %d", maxDailyCountLimit),
+		}
+	}
+	return nil
+}
+
+// Check refuses a transfer of amount when it breaks a limit, given what the
+// account already sent today.
+func (l TransferLimits) Check(amount decimal.Decimal, today TransferTotals) error {
+	if l.MaxTransferAmount != nil && amount.GreaterThan(*l.MaxTransferAmount) {
+		return &LimitExceededError{
+			Limit:   MaxTransferAmount,
+			Max:     *l.MaxTransferAmount,
+			Reached: amount,
+		}
+	}
+	if l.MaxDailyAmount != nil && today.Amount.Add(amount).GreaterThan(*l.MaxDailyAmount) {
+		return &LimitExceededError{
+			Limit:   MaxDailyAmount,
+			Max:     *l.MaxDailyAmount,
+			Reached: today.Amount.Add(amount),
+		}
+	}
+	if l.MaxDailyCount != nil && today.Count+1 > *l.MaxDailyCount {
+		return &LimitExceededError{
+			Limit:   MaxDailyCount,
+			Max:     decimal.NewFromInt(*l.MaxDailyCount),
+			Reached: decimal.NewFromInt(today.Count + 1),
+		}
+	}
+	return nil
+}
+
+func equalDecimals(a, b *decimal.Decimal) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func formatLimit(limit *decimal.Decimal) string {
+	if limit == nil {
+		return "none"
+	}
+	return limit.String()
+}
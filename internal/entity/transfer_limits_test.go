@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransferLimits_Check_AllowWithinLimits(t *testing.T) {
+	maxTransfer := decimal.NewFromInt(1000)
+	maxDaily := decimal.NewFromInt(2000)
+	maxCount := int64(3)
+	limits := TransferLimits{MaxTransferAmount: &maxTransfer, MaxDailyAmount: &maxDaily, MaxDailyCount: &maxCount}
+
+	err := limits.Check(decimal.NewFromInt(1000), TransferTotals{Count: 2, Amount: decimal.NewFromInt(1000)})
+
+	assert.Nil(t, err)
+	assert.Nil(t, TransferLimits{}.Check(decimal.NewFromInt(1000000), TransferTotals{Count: 100}))
+}
+
+func TestTransferLimits_Check_FailDueToExceededLimit(t *testing.T) {
+	maxTransfer := decimal.NewFromInt(1000)
+	maxDaily := decimal.NewFromInt(2000)
+	maxCount := int64(3)
+
+	for _, test := range []struct {
+		limits          TransferLimits
+		amount          decimal.Decimal
+		today           TransferTotals
+		expectedLimit   string
+		expectedReached string
+	}{
+		{TransferLimits{MaxTransferAmount: &maxTransfer}, decimal.RequireFromString("1000.01"), TransferTotals{}, MaxTransferAmount, "1000.01"},
+		{TransferLimits{MaxDailyAmount: &maxDaily}, decimal.NewFromInt(600), TransferTotals{Count: 1, Amount: decimal.NewFromInt(1500)}, MaxDailyAmount, "2100"},
+		{TransferLimits{MaxDailyCount: &maxCount}, decimal.NewFromInt(1), TransferTotals{Count: 3, Amount: decimal.NewFromInt(3)}, MaxDailyCount, "4"},
+	} {
+		err := test.limits.Check(test.amount, test.today)
+
+		var limitExceeded *LimitExceededError
+		assert.ErrorAs(t, err, &limitExceeded)
+		assert.Equal(t, test.expectedLimit, limitExceeded.Limit)
+		assert.Equal(t, test.expectedReached, limitExceeded.Reached.String())
+	}
+}
+
+func TestTransferLimits_Validate_FailDueToInvalidLimit(t *testing.T) {
+	negative := decimal.NewFromInt(-1)
+	fractional := decimal.RequireFromString("10.5")
+	negativeCount := int64(-1)
+	hugeCount := int64(1) << 31
+
+	for _, test := range []struct {
+		limits        TransferLimits
+		currency      Currency
+		expectedField string
+	}{
+		{TransferLimits{MaxTransferAmount: &negative}, BRL, MaxTransferAmount},
+		{TransferLimits{MaxDailyAmount: &fractional}, JPY, MaxDailyAmount},
+		{TransferLimits{MaxDailyCount: &negativeCount}, BRL, MaxDailyCount},
+		{TransferLimits{MaxDailyCount: &hugeCount}, BRL, MaxDailyCount},
+	} {
+		err := test.limits.Validate(test.currency)
+
+		var validationError *ValidationError
+		assert.ErrorAs(t, err, &validationError)
+		assert.Equal(t, test.expectedField, validationError.Field)
+	}
+}
+
+func TestTransferLimits_EqualAndString(t *testing.T) {
+	maxTransfer := decimal.NewFromInt(1000)
+	sameMaxTransfer := decimal.RequireFromString("1000.00")
+	maxCount := int64(10)
+	limits := TransferLimits{MaxTransferAmount: &maxTransfer, MaxDailyCount: &maxCount}
+
+	assert.True(t, limits.Equal(TransferLimits{MaxTransferAmount: &sameMaxTransfer, MaxDailyCount: &maxCount}))
+	assert.False(t, limits.Equal(TransferLimits{MaxTransferAmount: &maxTransfer}))
+	assert.Equal(t, "max_transfer_amount=1000 max_daily_amount=none max_daily_count=10", limits.String())
+}
@@ -0,0 +1,94 @@
+// Package fx holds the FX rate providers transfers between currencies are
+// priced with.
+package fx
+
+import (
+	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// inversePlaces is the precision of rates derived from the opposite pair.
+const inversePlaces = 12
+
+// StaticRateProvider serves a fixed set of rates. A pair missing from Rates
+// is priced with the inverse of the opposite pair when that one is known.
+type StaticRateProvider struct {
+	Rates map[Pair]decimal.Decimal
+}
+
+// Pair is a currency pair written "BRL/USD": the price of one real in
+// dollars.
+type Pair struct {
+	From entity.Currency
+	To   entity.Currency
+}
+
+func (p Pair) String() string {
+	return fmt.Sprintf("%s/%s", p.From, p.To)
+}
+
+// ParsePair reads a pair written "BRL/USD", in any case.
+func ParsePair(pair string) (Pair, error) {
+	from, to, found := strings.Cut(pair, "/")
+	if !found {
+		return Pair{}, fmt.Errorf("fx: pair %q should be written like \"BRL/USD\"", pair)
+	}
+	fromCurrency, err := entity.ParseCurrency(from)
+	if err != nil {
+		return Pair{}, fmt.Errorf("fx: pair %q: %w", pair, err)
+	}
+	toCurrency, err := entity.ParseCurrency(to)
+	if err != nil {
+		return Pair{}, fmt.Errorf("fx: pair %q: %w", pair, err)
+	}
+	return Pair{From: fromCurrency, To: toCurrency}, nil
+}
+
+// NewStaticRateProvider parses rates keyed by pairs written "BRL/USD".
+func NewStaticRateProvider(rates map[string]string) (*StaticRateProvider, error) {
+	provider := &StaticRateProvider{Rates: make(map[Pair]decimal.Decimal, len(rates))}
+	for key, value := range rates {
+		pair, err := ParsePair(key)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("fx: rate of %s: %w", pair, err)
+		}
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("fx: rate of %s should be positive, got %s", pair, value)
+		}
+		provider.Rates[pair] = rate
+	}
+	return provider, nil
+}
+
+// NewFileRateProvider loads a StaticRateProvider from a YAML or JSON file
+// mapping pairs to rates, e.g. {"BRL/USD": "0.1934"}.
+func NewFileRateProvider(path string) (*StaticRateProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fx: %w", err)
+	}
+	var rates map[string]string
+	if err = yaml.Unmarshal(content, &rates); err != nil {
+		return nil, fmt.Errorf("fx: %s: %w", path, err)
+	}
+	return NewStaticRateProvider(rates)
+}
+
+func (p *StaticRateProvider) GetRate(from entity.Currency, to entity.Currency) (decimal.Decimal, error) {
+	if rate, ok := p.Rates[Pair{From: from, To: to}]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.Rates[Pair{From: to, To: from}]; ok {
+		return decimal.NewFromInt(1).DivRound(rate, inversePlaces), nil
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s", gateway.ErrFXRateNotFound, Pair{From: from, To: to})
+}
@@ -0,0 +1,81 @@
+package fx
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticRateProvider_GetRate(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]string{"brl/usd": "0.2"})
+	require.Nil(t, err)
+
+	rate, err := provider.GetRate(entity.BRL, entity.USD)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0.2", rate.String())
+}
+
+func TestStaticRateProvider_GetRate_InvertOppositePair(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]string{"BRL/USD": "0.3"})
+	require.Nil(t, err)
+
+	rate, err := provider.GetRate(entity.USD, entity.BRL)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "3.333333333333", rate.String())
+}
+
+func TestStaticRateProvider_GetRate_FailDueToUnknownPair(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]string{"BRL/USD": "0.2"})
+	require.Nil(t, err)
+
+	_, err = provider.GetRate(entity.BRL, entity.EUR)
+
+	assert.ErrorIs(t, err, gateway.ErrFXRateNotFound)
+	assert.Equal(t, "fx rate not found: BRL/EUR", err.Error())
+}
+
+func TestNewStaticRateProvider_FailDueToInvalidRates(t *testing.T) {
+	for _, rates := range []map[string]string{
+		{"BRLUSD": "0.2"},
+		{"BRL/XYZ": "0.2"},
+		{"BRL/USD": "abc"},
+		{"BRL/USD": "-0.2"},
+	} {
+		_, err := NewStaticRateProvider(rates)
+
+		assert.NotNil(t, err, rates)
+	}
+}
+
+func TestNewFileRateProvider_LoadRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"BRL/USD": "0.2", "EUR/BRL": "5.5"}`), 0o600))
+
+	provider, err := NewFileRateProvider(path)
+	require.Nil(t, err)
+
+	assert.Len(t, provider.Rates, 2)
+	assert.Equal(t, "5.5", provider.Rates[Pair{From: entity.EUR, To: entity.BRL}].String())
+}
+
+func TestNewFileRateProvider_LoadYAMLRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	require.Nil(t, os.WriteFile(path, []byte("BRL/USD: 0.19345\n"), 0o600))
+
+	provider, err := NewFileRateProvider(path)
+	require.Nil(t, err)
+
+	assert.Equal(t, "0.19345", provider.Rates[Pair{From: entity.BRL, To: entity.USD}].String())
+}
+
+func TestNewFileRateProvider_FailDueToMissingFile(t *testing.T) {
+	_, err := NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+
+	assert.NotNil(t, err)
+}
@@ -9,5 +9,8 @@ import (
 type AccountGateway interface {
 	Create(account *entity.Account) error
 	GetByID(ID uuid.UUID) (*entity.Account, error)
-	UpdateBalance(ID uuid.UUID, amount decimal.Decimal) error
+	GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error)
+	UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error
+	UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error
+	UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error
 }
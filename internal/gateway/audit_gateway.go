@@ -0,0 +1,9 @@
+package gateway
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+)
+
+type AuditGateway interface {
+	Create(entry *entity.AuditEntry) error
+}
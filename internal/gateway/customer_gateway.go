@@ -6,6 +6,13 @@ import (
 )
 
 type CustomerGateway interface {
+	// Create returns a *DuplicateKeyError when the e-mail is already taken.
 	Create(customer *entity.Customer) error
 	GetByID(ID uuid.UUID) (*entity.Customer, error)
+	GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error)
+	// GetByEmail matches e-mails case-insensitively.
+	GetByEmail(email string) (*entity.Customer, error)
+	// Update stores the customer's name and email and bumps its UpdatedAt. Like
+	// Create, it returns a *DuplicateKeyError when the e-mail is taken.
+	Update(customer *entity.Customer) error
 }
@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// VersionConflictError is returned when an optimistic update finds the row
+// was modified since it was read.
+type VersionConflictError struct {
+	Entity  string
+	ID      uuid.UUID
+	Version int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s was modified concurrently | expected version: %d", e.Entity, e.ID, e.Version)
+}
+
+// DuplicateKeyError is returned when an insert collides with a row another
+// transaction stored first.
+type DuplicateKeyError struct {
+	Entity string
+	Key    string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s %s already exists", e.Entity, e.Key)
+}
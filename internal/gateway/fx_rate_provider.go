@@ -0,0 +1,17 @@
+package gateway
+
+import (
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// ErrFXRateNotFound is returned by an FXRateProvider asked for a pair it
+// can't price.
+var ErrFXRateNotFound = errors.New("fx rate not found")
+
+// FXRateProvider quotes how much of the to currency one unit of the from
+// currency is worth.
+type FXRateProvider interface {
+	GetRate(from entity.Currency, to entity.Currency) (decimal.Decimal, error)
+}
@@ -0,0 +1,14 @@
+package gateway
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"time"
+)
+
+type IdempotencyGateway interface {
+	Create(key *entity.IdempotencyKey) error
+	GetByKey(key string) (*entity.IdempotencyKey, error)
+	Delete(key string) error
+	// DeleteExpired removes the keys that expired by now and returns how many.
+	DeleteExpired(now time.Time) (int64, error)
+}
@@ -0,0 +1,10 @@
+package gateway
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+)
+
+type LedgerGateway interface {
+	Create(entries ...*entity.LedgerEntry) error
+	GetBalances() ([]*entity.LedgerBalance, error)
+}
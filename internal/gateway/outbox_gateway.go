@@ -0,0 +1,9 @@
+package gateway
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+)
+
+type OutboxGateway interface {
+	Create(event events.Event) error
+}
@@ -3,9 +3,32 @@ package gateway
 import (
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/google/uuid"
+	"time"
 )
 
 type TransactionGateway interface {
 	Create(transaction *entity.Transaction) error
 	GetByID(ID uuid.UUID) (*entity.Transaction, error)
+	GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error)
+	Update(transaction *entity.Transaction) error
+	ListByAccount(filter StatementFilter) ([]*entity.StatementEntry, error)
+	SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error)
+}
+
+// StatementFilter selects a page of an account's transactions, newest first.
+// Zero values leave the matching filter out.
+type StatementFilter struct {
+	AccountID uuid.UUID
+	Direction entity.TransferDirection
+	// From is inclusive and To exclusive.
+	From  *time.Time
+	To    *time.Time
+	After *StatementCursor
+	Limit int
+}
+
+// StatementCursor points at the last transaction of the previous page.
+type StatementCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
@@ -1,6 +1,8 @@
 package create_account
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
@@ -8,10 +10,13 @@ import (
 
 type CreateAccountCommand struct {
 	CustomerID uuid.UUID `json:"customer_id"`
+	// Currency is an ISO 4217 code, entity.DefaultCurrency when omitted.
+	Currency string `json:"currency"`
 }
 
 type CreateAccountOutput struct {
-	ID uuid.UUID `json:"id"`
+	ID       uuid.UUID       `json:"id"`
+	Currency entity.Currency `json:"currency"`
 }
 
 type CreateAccountUseCase struct {
@@ -30,12 +35,23 @@ func NewCreateAccountUseCase(
 }
 
 func (uc *CreateAccountUseCase) Execute(command CreateAccountCommand) (*CreateAccountOutput, error) {
+	currency := entity.DefaultCurrency
+	if command.Currency != "" {
+		var err error
+		if currency, err = entity.ParseCurrency(command.Currency); err != nil {
+			return nil, err
+		}
+	}
+
 	customer, err := uc.CustomerGateway.GetByID(command.CustomerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrCustomerNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	account, err := entity.NewAccount(customer)
+	account, err := entity.NewAccount(customer, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -45,5 +61,5 @@ func (uc *CreateAccountUseCase) Execute(command CreateAccountCommand) (*CreateAc
 		return nil, err
 	}
 
-	return &CreateAccountOutput{ID: account.ID}, nil
+	return &CreateAccountOutput{ID: account.ID, Currency: account.Currency}, nil
 }
@@ -1,6 +1,7 @@
 package create_account
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/google/uuid"
@@ -21,13 +22,14 @@ func TestCreateAccountUseCase_Execute_CreateSuccessfully(t *testing.T) {
 	accountGatewayMock.On("Create", m.AnythingOfType("*entity.Account")).
 		Return(nil)
 
-	command := CreateAccountCommand{customer.ID}
+	command := CreateAccountCommand{CustomerID: customer.ID}
 
 	useCase := NewCreateAccountUseCase(accountGatewayMock, customerGatewayMock)
 	output, err := useCase.Execute(command)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, output.ID)
+	assert.Equal(t, entity.DefaultCurrency, output.Currency)
 
 	customerGatewayMock.AssertExpectations(t)
 	customerGatewayMock.AssertNumberOfCalls(t, "GetByID", 1)
@@ -36,6 +38,47 @@ func TestCreateAccountUseCase_Execute_CreateSuccessfully(t *testing.T) {
 	accountGatewayMock.AssertNumberOfCalls(t, "Create", 1)
 }
 
+func TestCreateAccountUseCase_Execute_CreateInGivenCurrency(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	customerGatewayMock := &CustomerGatewayMock{}
+	accountGatewayMock := &AccountGatewayMock{}
+
+	customerGatewayMock.On("GetByID", customer.ID).
+		Return(customer, nil)
+
+	accountGatewayMock.On("Create", m.MatchedBy(func(account *entity.Account) bool {
+		return account.Currency == entity.USD
+	})).Return(nil)
+
+	command := CreateAccountCommand{CustomerID: customer.ID, Currency: "usd"}
+
+	useCase := NewCreateAccountUseCase(accountGatewayMock, customerGatewayMock)
+	output, err := useCase.Execute(command)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.USD, output.Currency)
+
+	accountGatewayMock.AssertExpectations(t)
+}
+
+func TestCreateAccountUseCase_Execute_FailDueToUnsupportedCurrency(t *testing.T) {
+	customerGatewayMock := &CustomerGatewayMock{}
+	accountGatewayMock := &AccountGatewayMock{}
+
+	command := CreateAccountCommand{CustomerID: uuid.New(), Currency: "XYZ"}
+
+	useCase := NewCreateAccountUseCase(accountGatewayMock, customerGatewayMock)
+	output, err := useCase.Execute(command)
+
+	var validationError *entity.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, "currency", validationError.Field)
+	assert.Nil(t, output)
+
+	customerGatewayMock.AssertNotCalled(t, "GetByID")
+	accountGatewayMock.AssertNotCalled(t, "Create")
+}
+
 func TestCreateAccountUseCase_Execute_FailDueToCustomerNotFound(t *testing.T) {
 	customerID := uuid.New()
 	expectedErrorMessage := "customer not found"
@@ -44,14 +87,14 @@ func TestCreateAccountUseCase_Execute_FailDueToCustomerNotFound(t *testing.T) {
 	accountGatewayMock := &AccountGatewayMock{}
 
 	customerGatewayMock.On("GetByID", customerID).
-		Return(&entity.Customer{}, errors.New(expectedErrorMessage))
+		Return(&entity.Customer{}, sql.ErrNoRows)
 
-	command := CreateAccountCommand{customerID}
+	command := CreateAccountCommand{CustomerID: customerID}
 
 	useCase := NewCreateAccountUseCase(accountGatewayMock, customerGatewayMock)
 	output, err := useCase.Execute(command)
 
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
 	assert.Equal(t, expectedErrorMessage, err.Error())
 	assert.Nil(t, output)
 
@@ -73,7 +116,7 @@ func TestCreateAccountUseCase_Execute_FailDueToGatewayError(t *testing.T) {
 	accountGatewayMock.On("Create", m.AnythingOfType("*entity.Account")).
 		Return(errors.New(expectedErrorMessage))
 
-	command := CreateAccountCommand{customer.ID}
+	command := CreateAccountCommand{CustomerID: customer.ID}
 
 	useCase := NewCreateAccountUseCase(accountGatewayMock, customerGatewayMock)
 	output, err := useCase.Execute(command)
@@ -93,7 +136,19 @@ type AccountGatewayMock struct {
 	m.Mock
 }
 
-func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal) error {
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
 	panic("implement me")
 }
 
@@ -120,3 +175,15 @@ func (m *CustomerGatewayMock) GetByID(ID uuid.UUID) (*entity.Customer, error) {
 	args := m.Called(ID)
 	return args.Get(0).(*entity.Customer), args.Error(1)
 }
+
+func (m *CustomerGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByEmail(email string) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) Update(customer *entity.Customer) error {
+	panic("implement me")
+}
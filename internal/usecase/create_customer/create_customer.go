@@ -1,6 +1,8 @@
 package create_customer
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
@@ -36,7 +38,21 @@ func (uc *CreateCustomerUseCase) Execute(command CreateCustomerCommand) (*Create
 		return nil, err
 	}
 
+	_, err = uc.CustomerGateway.GetByEmail(customer.Email)
+	if err == nil {
+		return nil, entity.ErrEmailAlreadyRegistered
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// The unique index still catches a concurrent registration of the same
+	// e-mail.
 	err = uc.CustomerGateway.Create(customer)
+	var duplicate *gateway.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		return nil, entity.ErrEmailAlreadyRegistered
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,10 @@
 package create_customer
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -11,6 +13,7 @@ import (
 
 func TestCreateCustomerUseCase_Execute_CreateSuccessfully(t *testing.T) {
 	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByEmail", "alexandrebrunodias@gmail.com").Return((*entity.Customer)(nil), sql.ErrNoRows)
 	customerGatewayMock.On("Create", mock.Anything).Return(nil)
 
 	expectedName := "alex"
@@ -40,6 +43,7 @@ func TestCreateCustomerUseCase_Execute_FailDueToGatewayError(t *testing.T) {
 	expectedErrorMessage := "gateway error"
 
 	gatewayMock := &CustomerGatewayMock{}
+	gatewayMock.On("GetByEmail", mock.Anything).Return((*entity.Customer)(nil), sql.ErrNoRows)
 	gatewayMock.On("Create", mock.Anything).Return(errors.New(expectedErrorMessage))
 
 	expectedName := "alex"
@@ -61,6 +65,33 @@ func TestCreateCustomerUseCase_Execute_FailDueToGatewayError(t *testing.T) {
 	gatewayMock.AssertNumberOfCalls(t, "Create", 1)
 }
 
+func TestCreateCustomerUseCase_Execute_FailDueToEmailAlreadyRegistered(t *testing.T) {
+	existing, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+
+	gatewayMock := &CustomerGatewayMock{}
+	gatewayMock.On("GetByEmail", "alexandrebrunodias@gmail.com").Return(existing, nil)
+
+	useCase := NewCreateCustomerUseCase(gatewayMock)
+	output, err := useCase.Execute(CreateCustomerCommand{Name: "other alex", Email: " AlexandreBrunoDias@gmail.com"})
+
+	assert.ErrorIs(t, err, entity.ErrEmailAlreadyRegistered)
+	assert.Nil(t, output)
+	gatewayMock.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateCustomerUseCase_Execute_FailDueToConcurrentRegistration(t *testing.T) {
+	gatewayMock := &CustomerGatewayMock{}
+	gatewayMock.On("GetByEmail", mock.Anything).Return((*entity.Customer)(nil), sql.ErrNoRows)
+	gatewayMock.On("Create", mock.Anything).
+		Return(&gateway.DuplicateKeyError{Entity: "customer", Key: "alexandrebrunodias@gmail.com"})
+
+	useCase := NewCreateCustomerUseCase(gatewayMock)
+	output, err := useCase.Execute(CreateCustomerCommand{Name: "alex", Email: "alexandrebrunodias@gmail.com"})
+
+	assert.ErrorIs(t, err, entity.ErrEmailAlreadyRegistered)
+	assert.Nil(t, output)
+}
+
 type CustomerGatewayMock struct {
 	mock.Mock
 }
@@ -74,3 +105,16 @@ func (m *CustomerGatewayMock) GetByID(ID uuid.UUID) (*entity.Customer, error) {
 	args := m.Called(ID)
 	return args.Get(0).(*entity.Customer), args.Error(1)
 }
+
+func (m *CustomerGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByEmail(email string) (*entity.Customer, error) {
+	args := m.Called(email)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) Update(customer *entity.Customer) error {
+	panic("implement me")
+}
@@ -2,71 +2,180 @@ package create_transaction
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/transfer"
 	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
 	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"time"
 )
 
-const TransactionCreated = "wallet.core.transaction.created"
+const (
+	TransactionCreated    = wallet.TransactionCreated
+	AccountBalanceUpdated = wallet.AccountBalanceUpdated
+)
+
+var ErrIdempotencyKeyReused = &entity.UnprocessableError{
+	Code:    "idempotency_key_reused",
+	Message: "'Idempotency-Key' was already used with a different request",
+}
 
 type CreateTransactionCommand struct {
-	FromAccountID uuid.UUID       `json:"from_account_id"`
-	ToAccountID   uuid.UUID       `json:"to_account_id"`
-	Amount        decimal.Decimal `json:"amount"`
+	FromAccountID  uuid.UUID       `json:"from_account_id"`
+	ToAccountID    uuid.UUID       `json:"to_account_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	IdempotencyKey string          `json:"-"`
 }
 
+// fingerprint identifies the request body a key was first used with, so a
+// reused key can be told apart from a genuine retry.
+func (c CreateTransactionCommand) fingerprint() string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s|%s|%s", c.FromAccountID, c.ToAccountID, c.Amount.String(),
+	)))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateTransactionOutput reports Amount in the currency of the debited
+// account and DestinationAmount in the one of the credited account, priced at
+// FXRate when they differ.
 type CreateTransactionOutput struct {
-	ID            uuid.UUID       `json:"id"`
-	FromAccountID uuid.UUID       `json:"from_account_id"`
-	ToAccountID   uuid.UUID       `json:"to_account_id"`
-	Amount        decimal.Decimal `json:"amount"`
+	ID                  uuid.UUID        `json:"id"`
+	FromAccountID       uuid.UUID        `json:"from_account_id"`
+	ToAccountID         uuid.UUID        `json:"to_account_id"`
+	Amount              decimal.Decimal  `json:"amount"`
+	Currency            entity.Currency  `json:"currency"`
+	DestinationAmount   decimal.Decimal  `json:"destination_amount"`
+	DestinationCurrency entity.Currency  `json:"destination_currency"`
+	FXRate              *decimal.Decimal `json:"fx_rate,omitempty"`
+	Status              entity.Status    `json:"status"`
+	FailureReason       string           `json:"failure_reason,omitempty"`
+	Replayed            bool             `json:"-"`
 }
 
+// LockingStrategy decides how concurrent transfers touching the same account
+// are serialized.
+type LockingStrategy string
+
+const (
+	// PessimisticLocking reads both accounts FOR UPDATE, so transfers on the
+	// same account wait for each other.
+	PessimisticLocking LockingStrategy = "pessimistic"
+	// OptimisticLocking reads without locks and relies on the account version
+	// check, retrying the whole unit of work on conflict.
+	OptimisticLocking LockingStrategy = "optimistic"
+)
+
+const (
+	DefaultMaxConflictRetries = 3
+	DefaultIdempotencyKeyTTL  = 24 * time.Hour
+)
+
 type CreateTransactionUseCase struct {
-	UnitOfWork     uow.UnitOfWorkInterface
-	EventPublisher events.EventPublisherInterface
+	UnitOfWork         uow.UnitOfWorkInterface
+	Locking            LockingStrategy
+	MaxConflictRetries int
+	IdempotencyKeyTTL  time.Duration
+	// FXRateProvider prices transfers between currencies, which are refused
+	// without one.
+	FXRateProvider gateway.FXRateProvider
+	// Rounding brings converted amounts to the minor unit of their currency.
+	Rounding entity.RoundingMode
 }
 
-func NewCreateTransactionUseCase(
-	UnitOfWork uow.UnitOfWorkInterface,
-	EventPublisher events.EventPublisherInterface,
-) *CreateTransactionUseCase {
+func NewCreateTransactionUseCase(UnitOfWork uow.UnitOfWorkInterface) *CreateTransactionUseCase {
 	return &CreateTransactionUseCase{
-		UnitOfWork:     UnitOfWork,
-		EventPublisher: EventPublisher,
+		UnitOfWork:         UnitOfWork,
+		Locking:            PessimisticLocking,
+		MaxConflictRetries: DefaultMaxConflictRetries,
+		IdempotencyKeyTTL:  DefaultIdempotencyKeyTTL,
+		Rounding:           entity.DefaultRoundingMode,
 	}
 }
 
 func (uc *CreateTransactionUseCase) Execute(ctx context.Context, command CreateTransactionCommand) (*CreateTransactionOutput, error) {
+	for attempt := 0; ; attempt++ {
+		output, err := uc.execute(ctx, command)
+		if err == nil {
+			return output, nil
+		}
+
+		var failed *entity.TransactionFailedError
+		if errors.As(err, &failed) {
+			if recordErr := uc.recordFailure(ctx, command, failed.Transaction); recordErr != nil {
+				return nil, recordErr
+			}
+			return nil, err
+		}
+
+		// A duplicate idempotency key means a concurrent retry committed
+		// first: running again replays its outcome.
+		var conflict *gateway.VersionConflictError
+		var duplicate *gateway.DuplicateKeyError
+		retryable := errors.As(err, &conflict) || errors.As(err, &duplicate)
+		if !retryable || attempt >= uc.MaxConflictRetries {
+			return nil, err
+		}
+	}
+}
+
+func (uc *CreateTransactionUseCase) execute(ctx context.Context, command CreateTransactionCommand) (*CreateTransactionOutput, error) {
 	output := &CreateTransactionOutput{}
-	err := uc.UnitOfWork.Do(ctx, func(_ *uow.UnitOfWork) error {
-		accountGateway := uc.getAccountGateway(ctx)
-		transactionGateway := uc.getTransactionGateway(ctx)
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		accountGateway := getAccountGateway(ctx, unitOfWork)
+		transactionGateway := getTransactionGateway(ctx, unitOfWork)
+		ledgerGateway := getLedgerGateway(ctx, unitOfWork)
+		outboxGateway := getOutboxGateway(ctx, unitOfWork)
+
+		if command.IdempotencyKey != "" {
+			replayed, err := replay(getIdempotencyGateway(ctx, unitOfWork), command, output)
+			if err != nil || replayed {
+				return err
+			}
+		}
+
+		getAccount := accountGateway.GetByIDForUpdate
+		if uc.Locking == OptimisticLocking {
+			getAccount = accountGateway.GetByID
+		}
+
+		accounts, err := transfer.LoadAccounts(getAccount, command.FromAccountID, command.ToAccountID)
+		if err != nil {
+			return err
+		}
+		fromAccount := accounts[command.FromAccountID]
+		toAccount := accounts[command.ToAccountID]
 
-		fromAccount, err := accountGateway.GetByID(command.FromAccountID)
+		conversion, err := uc.conversion(fromAccount, toAccount)
 		if err != nil {
 			return err
 		}
 
-		toAccount, err := accountGateway.GetByID(command.ToAccountID)
+		err = checkTransferLimits(transactionGateway, fromAccount, command.Amount)
 		if err != nil {
 			return err
 		}
 
-		transaction, err := entity.NewTransaction(fromAccount, toAccount, command.Amount)
+		transaction, err := entity.NewTransaction(fromAccount, toAccount, command.Amount, conversion)
 		if err != nil {
 			return err
 		}
 
-		err = accountGateway.UpdateBalance(fromAccount.ID, transaction.FromAccount.Balance)
+		err = transfer.UpdateBalance(accountGateway, transaction.FromAccount)
 		if err != nil {
 			return err
 		}
 
-		err = accountGateway.UpdateBalance(toAccount.ID, transaction.ToAccount.Balance)
+		err = transfer.UpdateBalance(accountGateway, transaction.ToAccount)
 		if err != nil {
 			return err
 		}
@@ -76,33 +185,221 @@ func (uc *CreateTransactionUseCase) Execute(ctx context.Context, command CreateT
 			return err
 		}
 
-		output.ID = transaction.ID
-		output.FromAccountID = fromAccount.ID
-		output.ToAccountID = toAccount.ID
-		output.Amount = transaction.Amount
+		err = ledgerGateway.Create(transaction.LedgerEntries()...)
+		if err != nil {
+			return err
+		}
+
+		fillOutput(output, transaction)
+
+		// The event is stored alongside the balance updates and relayed to the
+		// broker after commit, so a transfer can never lose its event. Keying
+		// it by the debited account keeps that account's events in order.
+		event := events.NewPayloadEvent(wallet.TransactionCreatedV1{
+			ID:                  output.ID,
+			FromAccountID:       output.FromAccountID,
+			ToAccountID:         output.ToAccountID,
+			Amount:              output.Amount,
+			Currency:            string(output.Currency),
+			DestinationAmount:   &output.DestinationAmount,
+			DestinationCurrency: string(output.DestinationCurrency),
+			FXRate:              output.FXRate,
+			Status:              string(output.Status),
+		}).WithKey(fromAccount.ID.String())
+		err = outboxGateway.Create(*event)
+		if err != nil {
+			return err
+		}
+
+		err = outboxGateway.Create(*transfer.NewBalanceUpdatedEvent(transaction, fromAccount.ID))
+		if err != nil {
+			return err
+		}
+
+		if command.IdempotencyKey != "" {
+			return uc.remember(getIdempotencyGateway(ctx, unitOfWork), command, output)
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	event := events.NewEvent(TransactionCreated, output)
-	uc.EventPublisher.Register(*event).Publish()
 	return output, nil
 }
 
-func (uc *CreateTransactionUseCase) getAccountGateway(ctx context.Context) gateway.AccountGateway {
-	repository, err := uc.UnitOfWork.GetRepository(ctx, "AccountGateway")
+// recordFailure stores a transfer the accounts refused in a unit of work of
+// its own, since the one that attempted it was rolled back, so support can
+// still see what happened to it. The failure is remembered under the
+// command's idempotency key too, so a retry replays it instead of storing
+// another failed transfer. Should a concurrent retry remember it first, that
+// one's record is kept.
+func (uc *CreateTransactionUseCase) recordFailure(
+	ctx context.Context,
+	command CreateTransactionCommand,
+	transaction *entity.Transaction,
+) error {
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		err := getTransactionGateway(ctx, unitOfWork).Create(transaction)
+		if err != nil || command.IdempotencyKey == "" {
+			return err
+		}
+
+		output := &CreateTransactionOutput{}
+		fillOutput(output, transaction)
+		return uc.remember(getIdempotencyGateway(ctx, unitOfWork), command, output)
+	})
+	var duplicate *gateway.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		return nil
+	}
+	return err
+}
+
+func fillOutput(output *CreateTransactionOutput, transaction *entity.Transaction) {
+	output.ID = transaction.ID
+	output.FromAccountID = transaction.FromAccount.ID
+	output.ToAccountID = transaction.ToAccount.ID
+	output.Amount = transaction.Amount
+	output.Currency = transaction.FromAccount.Currency
+	output.DestinationAmount = transaction.DestinationAmount
+	output.DestinationCurrency = transaction.ToAccount.Currency
+	output.FXRate = transaction.FXRate
+	output.Status = transaction.Status
+	output.FailureReason = transaction.FailureReason
+}
+
+// conversion prices a transfer between accounts in different currencies at
+// the provider's current rate. Without a provider it leaves the transfer for
+// entity.NewTransaction to refuse.
+func (uc *CreateTransactionUseCase) conversion(fromAccount, toAccount *entity.Account) (*entity.Conversion, error) {
+	if fromAccount.Currency == toAccount.Currency || uc.FXRateProvider == nil {
+		return nil, nil
+	}
+
+	rate, err := uc.FXRateProvider.GetRate(fromAccount.Currency, toAccount.Currency)
+	if errors.Is(err, gateway.ErrFXRateNotFound) {
+		return nil, &entity.UnprocessableError{
+			Code:    "fx_rate_unavailable",
+			Message: fmt.Sprintf("no FX rate is available from %s to %s", fromAccount.Currency, toAccount.Currency),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Conversion{Rate: rate, Rounding: uc.Rounding}, nil
+}
+
+// checkTransferLimits refuses a transfer breaking the limits of the debited
+// account. Days start at midnight UTC, and the transfers of the day are only
+// summed when a daily limit applies. The sum can't go stale before commit:
+// a concurrent transfer from the same account either waits for its lock or,
+// with optimistic locking, bumps its version and makes this one retry.
+func checkTransferLimits(
+	transactionGateway gateway.TransactionGateway,
+	account *entity.Account,
+	amount decimal.Decimal,
+) error {
+	var today entity.TransferTotals
+	if account.TransferLimits.HasDailyLimits() {
+		totals, err := transactionGateway.SumOutgoing(account.ID, time.Now().UTC().Truncate(24*time.Hour))
+		if err != nil {
+			return err
+		}
+		today = *totals
+	}
+	return account.TransferLimits.Check(amount, today)
+}
+
+// replay fills output with the stored outcome when the command's idempotency
+// key was already used with the same request and has not expired yet. A
+// stored failure is returned as an error carrying its reason.
+func replay(
+	idempotencyGateway gateway.IdempotencyGateway,
+	command CreateTransactionCommand,
+	output *CreateTransactionOutput,
+) (bool, error) {
+	stored, err := idempotencyGateway.GetByKey(command.IdempotencyKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if stored.IsExpired(time.Now().UTC()) {
+		return false, idempotencyGateway.Delete(stored.Key)
+	}
+	if !stored.Matches(command.fingerprint()) {
+		return false, ErrIdempotencyKeyReused
+	}
+
+	if err = json.Unmarshal(stored.Response, output); err != nil {
+		return false, err
+	}
+	if output.Status == entity.Failed {
+		return false, &entity.UnprocessableError{
+			Code:    output.FailureReason,
+			Message: fmt.Sprintf("transaction %s failed: %s", output.ID, output.FailureReason),
+		}
+	}
+	output.Replayed = true
+	return true, nil
+}
+
+func (uc *CreateTransactionUseCase) remember(
+	idempotencyGateway gateway.IdempotencyGateway,
+	command CreateTransactionCommand,
+	output *CreateTransactionOutput,
+) error {
+	response, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	key, err := entity.NewIdempotencyKey(command.IdempotencyKey, command.fingerprint(), response, uc.IdempotencyKeyTTL)
+	if err != nil {
+		return err
+	}
+	return idempotencyGateway.Create(key)
+}
+
+func getAccountGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AccountGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AccountGateway")
 	if err != nil {
 		panic(err)
 	}
 	return repository.(gateway.AccountGateway)
 }
 
-func (uc *CreateTransactionUseCase) getTransactionGateway(ctx context.Context) gateway.TransactionGateway {
-	repository, err := uc.UnitOfWork.GetRepository(ctx, "TransactionGateway")
+func getTransactionGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.TransactionGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "TransactionGateway")
 	if err != nil {
 		panic(err)
 	}
 	return repository.(gateway.TransactionGateway)
 }
+
+func getOutboxGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.OutboxGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "OutboxGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.OutboxGateway)
+}
+
+func getLedgerGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.LedgerGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "LedgerGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.LedgerGateway)
+}
+
+func getIdempotencyGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.IdempotencyGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "IdempotencyGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.IdempotencyGateway)
+}
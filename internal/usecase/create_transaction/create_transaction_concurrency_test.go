@@ -0,0 +1,130 @@
+//go:build integration
+
+package create_transaction
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/database/postgres"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// TestCreateTransactionUseCase_Execute_ConcurrentTransfersConserveBalance needs
+// a real Postgres, since row locks can't be exercised against sqlite. It is
+// built with the integration tag only, and WALLET_TEST_POSTGRES_DSN points it
+// at a disposable database: `make test-integration` starts one.
+func TestCreateTransactionUseCase_Execute_ConcurrentTransfersConserveBalance(t *testing.T) {
+	dsn := os.Getenv("WALLET_TEST_POSTGRES_DSN")
+	require.NotEmpty(t, dsn, "WALLET_TEST_POSTGRES_DSN is not set")
+
+	db, err := sql.Open("postgres", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+	applyMigrations(t, db)
+
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	require.Nil(t, postgres.NewCustomerPgGateway(db).Create(customer))
+
+	unitOfWork := uow.NewUnitOfWork(context.Background(), db)
+	unitOfWork.Add("AccountGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewAccountPgGateway(tx)
+	})
+	unitOfWork.Add("TransactionGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewTransactionPgGateway(tx)
+	})
+	unitOfWork.Add("LedgerGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewLedgerPgGateway(tx)
+	})
+	unitOfWork.Add("IdempotencyGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewIdempotencyPgGateway(tx)
+	})
+	unitOfWork.Add("OutboxGateway", func(tx *sql.Tx) interface{} {
+		return postgres.NewOutboxPgGateway(tx)
+	})
+
+	for _, locking := range []LockingStrategy{PessimisticLocking, OptimisticLocking} {
+		t.Run(string(locking), func(t *testing.T) {
+			useCase := NewCreateTransactionUseCase(unitOfWork)
+			useCase.Locking = locking
+			useCase.MaxConflictRetries = 100
+			assertConcurrentTransfersConserveBalance(t, db, customer, useCase)
+		})
+	}
+}
+
+func assertConcurrentTransfersConserveBalance(
+	t *testing.T,
+	db *sql.DB,
+	customer *entity.Customer,
+	useCase *CreateTransactionUseCase,
+) {
+	accountGateway := postgres.NewAccountPgGateway(db)
+	accountOne, _ := entity.NewAccount(customer, entity.BRL)
+	accountTwo, _ := entity.NewAccount(customer, entity.BRL)
+	initialBalance := decimal.NewFromInt(1000)
+	for _, account := range []*entity.Account{accountOne, accountTwo} {
+		require.Nil(t, accountGateway.Create(account))
+		require.Nil(t, accountGateway.UpdateBalance(account.ID, initialBalance, account.Version))
+	}
+
+	// Transfers run in both directions so a lock taken in request order
+	// instead of ID order would deadlock.
+	transfers := 50
+	amount := decimal.NewFromInt(30)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < transfers; i++ {
+		command := CreateTransactionCommand{FromAccountID: accountOne.ID, ToAccountID: accountTwo.ID, Amount: amount}
+		if i%2 == 1 {
+			command.FromAccountID, command.ToAccountID = command.ToAccountID, command.FromAccountID
+		}
+		wg.Add(1)
+		go func(command CreateTransactionCommand) {
+			defer wg.Done()
+			// Insufficient funds is an acceptable outcome, lost updates are not.
+			_, _ = useCase.Execute(context.Background(), command)
+		}(command)
+	}
+	wg.Wait()
+
+	actualAccountOne, err := accountGateway.GetByID(accountOne.ID)
+	require.Nil(t, err)
+	actualAccountTwo, err := accountGateway.GetByID(accountTwo.ID)
+	require.Nil(t, err)
+
+	total := actualAccountOne.Balance.Add(actualAccountTwo.Balance)
+	assert.Equal(t, initialBalance.Mul(decimal.NewFromInt(2)).String(), total.String())
+	assert.False(t, actualAccountOne.Balance.IsNegative())
+	assert.False(t, actualAccountTwo.Balance.IsNegative())
+
+	var transferred decimal.Decimal
+	err = db.QueryRow(
+		`SELECT COALESCE(SUM(CASE WHEN from_account_id = $1 THEN -amount ELSE amount END), 0)
+			FROM transactions WHERE (from_account_id = $1 OR to_account_id = $1) AND status <> 'failed'`,
+		accountOne.ID,
+	).Scan(&transferred)
+	require.Nil(t, err)
+	assert.Equal(t, initialBalance.Add(transferred).String(), actualAccountOne.Balance.String())
+}
+
+func applyMigrations(t *testing.T, db *sql.DB) {
+	files, err := filepath.Glob("../../../migrations/*.up.sql")
+	require.Nil(t, err)
+	sort.Strings(files)
+
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		require.Nil(t, err)
+		_, err = db.Exec(string(query))
+		require.Nil(t, err, file)
+	}
+}
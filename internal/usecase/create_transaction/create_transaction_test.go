@@ -1,25 +1,31 @@
 package create_transaction
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
 	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
 	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	m "github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 func TestCreateTransactionUseCase_Execute_CreateSuccessfully(t *testing.T) {
 	fromCustomer, _ := entity.NewCustomer("fromCustomer", "alexandrebrunodias@gmail.com")
-	expectedFromAccount, _ := entity.NewAccount(fromCustomer)
+	expectedFromAccount, _ := entity.NewAccount(fromCustomer, entity.BRL)
 	_ = expectedFromAccount.Credit(decimal.NewFromInt(2000))
 
 	toCustomer, _ := entity.NewCustomer("toCustomer", "alexandrebrunodias@gmail.com")
-	expectedToAccount, _ := entity.NewAccount(toCustomer)
+	expectedToAccount, _ := entity.NewAccount(toCustomer, entity.BRL)
 
 	expectedAmount := decimal.NewFromInt(1000)
 
@@ -29,25 +35,612 @@ func TestCreateTransactionUseCase_Execute_CreateSuccessfully(t *testing.T) {
 		Amount:        expectedAmount,
 	}
 
-	unitOfWorkMock := &UnitOfWorkMock{}
-	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", expectedFromAccount.ID).Return(expectedFromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", expectedToAccount.ID).Return(expectedToAccount, nil)
+	accountGatewayMock.On("UpdateBalance", expectedFromAccount.ID, decimal.NewFromInt(1000), int64(0)).Return(nil)
+	accountGatewayMock.On("UpdateBalance", expectedToAccount.ID, decimal.NewFromInt(1000), int64(0)).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.AnythingOfType("*entity.Transaction")).Return(nil)
 
-	eventPublisherMock := &EventPublisherMock{}
-	eventPublisherMock.On("Register", m.Anything).Return(eventPublisherMock)
-	eventPublisherMock.On("Publish", m.Anything)
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.TransactionCreatedV1)
+		return event.Name == TransactionCreated && event.SchemaVersion == 1 &&
+			event.Key == expectedFromAccount.ID.String() &&
+			ok && content.FromAccountID == expectedFromAccount.ID
+	})).Return(nil)
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.AccountBalanceUpdatedV1)
+		return event.Name == AccountBalanceUpdated && event.Key == expectedFromAccount.ID.String() && ok &&
+			content.FromAccountID == expectedFromAccount.ID &&
+			content.FromAccountBalance.Equal(decimal.NewFromInt(1000)) && content.FromAccountVersion == 1 &&
+			content.ToAccountID == expectedToAccount.ID &&
+			content.ToAccountBalance.Equal(decimal.NewFromInt(1000)) && content.ToAccountVersion == 1
+	})).Return(nil)
 
-	useCase := NewCreateTransactionUseCase(unitOfWorkMock, eventPublisherMock)
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
 	output, err := useCase.Execute(context.Background(), command)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, output.ID)
+	assert.Equal(t, expectedFromAccount.ID, output.FromAccountID)
+	assert.Equal(t, expectedToAccount.ID, output.ToAccountID)
+	assert.Equal(t, expectedAmount, output.Amount)
+	assert.Equal(t, entity.Completed, output.Status)
 
 	unitOfWorkMock.AssertExpectations(t)
 	unitOfWorkMock.AssertNumberOfCalls(t, "Do", 1)
 
-	eventPublisherMock.AssertExpectations(t)
-	eventPublisherMock.AssertNumberOfCalls(t, "Register", 1)
-	eventPublisherMock.AssertNumberOfCalls(t, "Publish", 1)
+	accountGatewayMock.AssertExpectations(t)
+	accountGatewayMock.AssertNumberOfCalls(t, "GetByIDForUpdate", 2)
+	accountGatewayMock.AssertNumberOfCalls(t, "UpdateBalance", 2)
+	accountGatewayMock.AssertNotCalled(t, "GetByID")
+
+	transactionGatewayMock.AssertExpectations(t)
+	outboxGatewayMock.AssertExpectations(t)
+}
+
+func TestCreateTransactionUseCase_Execute_LockAccountsInAscendingIDOrder(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	lowerAccount, _ := entity.NewAccount(customer, entity.BRL)
+	higherAccount, _ := entity.NewAccount(customer, entity.BRL)
+	if bytes.Compare(lowerAccount.ID[:], higherAccount.ID[:]) > 0 {
+		lowerAccount, higherAccount = higherAccount, lowerAccount
+	}
+	_ = higherAccount.Credit(decimal.NewFromInt(2000))
+
+	command := CreateTransactionCommand{
+		FromAccountID: higherAccount.ID,
+		ToAccountID:   lowerAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	var lockOrder []uuid.UUID
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", lowerAccount.ID).
+		Run(func(args m.Arguments) { lockOrder = append(lockOrder, args.Get(0).(uuid.UUID)) }).
+		Return(lowerAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", higherAccount.ID).
+		Run(func(args m.Arguments) { lockOrder = append(lockOrder, args.Get(0).(uuid.UUID)) }).
+		Return(higherAccount, nil)
+	accountGatewayMock.On("UpdateBalance", m.Anything, m.Anything, m.Anything).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.Anything).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	_, err := useCase.Execute(context.Background(), command)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uuid.UUID{lowerAccount.ID, higherAccount.ID}, lockOrder)
+}
+
+func TestCreateTransactionUseCase_Execute_RetryOnVersionConflict(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByID", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", fromAccount.ID, m.Anything, m.Anything).
+		Return(&gateway.VersionConflictError{Entity: "account", ID: fromAccount.ID}).Once()
+	accountGatewayMock.On("UpdateBalance", m.Anything, m.Anything, m.Anything).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.Anything).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	useCase.Locking = OptimisticLocking
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, output)
+
+	unitOfWorkMock.AssertNumberOfCalls(t, "Do", 2)
+	accountGatewayMock.AssertNotCalled(t, "GetByIDForUpdate")
+	transactionGatewayMock.AssertNumberOfCalls(t, "Create", 1)
+	outboxGatewayMock.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToVersionConflictRetriesExhausted(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByID", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", m.Anything, m.Anything, m.Anything).
+		Return(&gateway.VersionConflictError{Entity: "account", ID: fromAccount.ID})
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, &TransactionGatewayMock{}, &OutboxGatewayMock{})
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	useCase.Locking = OptimisticLocking
+	useCase.MaxConflictRetries = 2
+	output, err := useCase.Execute(context.Background(), command)
+
+	var conflict *gateway.VersionConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Nil(t, output)
+
+	unitOfWorkMock.AssertNumberOfCalls(t, "Do", 3)
+}
+
+func TestCreateTransactionUseCase_Execute_StoreIdempotencyKey(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         decimal.NewFromInt(1000),
+		IdempotencyKey: "retry-key",
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", m.Anything, m.Anything, m.Anything).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.Anything).Return(nil)
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("GetByKey", command.IdempotencyKey).Return((*entity.IdempotencyKey)(nil), sql.ErrNoRows)
+	idempotencyGatewayMock.On("Create", m.MatchedBy(func(key *entity.IdempotencyKey) bool {
+		return key.Key == command.IdempotencyKey && key.Fingerprint == command.fingerprint()
+	})).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.Repositories["IdempotencyGateway"] = idempotencyGatewayMock
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.Nil(t, err)
+	assert.False(t, output.Replayed)
+
+	idempotencyGatewayMock.AssertExpectations(t)
+	transactionGatewayMock.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestCreateTransactionUseCase_Execute_ReplayStoredOutput(t *testing.T) {
+	command := CreateTransactionCommand{
+		FromAccountID:  uuid.New(),
+		ToAccountID:    uuid.New(),
+		Amount:         decimal.NewFromInt(1000),
+		IdempotencyKey: "retry-key",
+	}
+	expectedOutput := CreateTransactionOutput{
+		ID:            uuid.New(),
+		FromAccountID: command.FromAccountID,
+		ToAccountID:   command.ToAccountID,
+		Amount:        command.Amount,
+	}
+	response, _ := json.Marshal(expectedOutput)
+	storedKey, _ := entity.NewIdempotencyKey(command.IdempotencyKey, command.fingerprint(), response, time.Hour)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	transactionGatewayMock := &TransactionGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("GetByKey", command.IdempotencyKey).Return(storedKey, nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.Repositories["IdempotencyGateway"] = idempotencyGatewayMock
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.Nil(t, err)
+	assert.True(t, output.Replayed)
+	assert.Equal(t, expectedOutput.ID, output.ID)
+	assert.Equal(t, expectedOutput.Amount.String(), output.Amount.String())
+
+	accountGatewayMock.AssertNotCalled(t, "GetByIDForUpdate")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+	idempotencyGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToIdempotencyKeyReusedWithDifferentBody(t *testing.T) {
+	command := CreateTransactionCommand{
+		FromAccountID:  uuid.New(),
+		ToAccountID:    uuid.New(),
+		Amount:         decimal.NewFromInt(1000),
+		IdempotencyKey: "retry-key",
+	}
+	storedKey, _ := entity.NewIdempotencyKey(command.IdempotencyKey, "another fingerprint", []byte(`{}`), time.Hour)
+
+	accountGatewayMock := &AccountGatewayMock{}
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("GetByKey", command.IdempotencyKey).Return(storedKey, nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, &TransactionGatewayMock{}, &OutboxGatewayMock{})
+	unitOfWorkMock.Repositories["IdempotencyGateway"] = idempotencyGatewayMock
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "GetByIDForUpdate")
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToInsufficientFunds(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	var insufficientFunds *entity.InsufficientFundsError
+	assert.ErrorAs(t, err, &insufficientFunds)
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+
+	// The failed attempt is stored by a second unit of work, outside the one
+	// that was rolled back.
+	unitOfWorkMock.AssertNumberOfCalls(t, "Do", 2)
+	transactionGatewayMock.AssertNumberOfCalls(t, "Create", 1)
+	failed := transactionGatewayMock.Calls[0].Arguments.Get(0).(*entity.Transaction)
+	assert.Equal(t, entity.Failed, failed.Status)
+	assert.Equal(t, entity.FailureInsufficientFunds, failed.FailureReason)
+	assert.Equal(t, fromAccount.ID, failed.FromAccount.ID)
+	assert.Equal(t, "1000", failed.Amount.String())
+}
+
+func TestCreateTransactionUseCase_Execute_RememberFailureUnderIdempotencyKey(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         decimal.NewFromInt(1000),
+		IdempotencyKey: "retry-key",
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("GetByKey", command.IdempotencyKey).Return((*entity.IdempotencyKey)(nil), sql.ErrNoRows)
+	idempotencyGatewayMock.On("Create", m.Anything).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, &OutboxGatewayMock{})
+	unitOfWorkMock.Repositories["IdempotencyGateway"] = idempotencyGatewayMock
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	_, err := useCase.Execute(context.Background(), command)
+
+	var insufficientFunds *entity.InsufficientFundsError
+	assert.ErrorAs(t, err, &insufficientFunds)
+
+	idempotencyGatewayMock.AssertNumberOfCalls(t, "Create", 1)
+	stored := idempotencyGatewayMock.Calls[1].Arguments.Get(0).(*entity.IdempotencyKey)
+	assert.Equal(t, command.fingerprint(), stored.Fingerprint)
+
+	var remembered CreateTransactionOutput
+	assert.Nil(t, json.Unmarshal(stored.Response, &remembered))
+	assert.Equal(t, entity.Failed, remembered.Status)
+	assert.Equal(t, entity.FailureInsufficientFunds, remembered.FailureReason)
+}
+
+func TestCreateTransactionUseCase_Execute_ReplayStoredFailure(t *testing.T) {
+	command := CreateTransactionCommand{
+		FromAccountID:  uuid.New(),
+		ToAccountID:    uuid.New(),
+		Amount:         decimal.NewFromInt(1000),
+		IdempotencyKey: "retry-key",
+	}
+	response, _ := json.Marshal(CreateTransactionOutput{
+		ID:            uuid.New(),
+		FromAccountID: command.FromAccountID,
+		ToAccountID:   command.ToAccountID,
+		Amount:        command.Amount,
+		Status:        entity.Failed,
+		FailureReason: entity.FailureInsufficientFunds,
+	})
+	storedKey, _ := entity.NewIdempotencyKey(command.IdempotencyKey, command.fingerprint(), response, time.Hour)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	transactionGatewayMock := &TransactionGatewayMock{}
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("GetByKey", command.IdempotencyKey).Return(storedKey, nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, &OutboxGatewayMock{})
+	unitOfWorkMock.Repositories["IdempotencyGateway"] = idempotencyGatewayMock
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	var unprocessable *entity.UnprocessableError
+	assert.ErrorAs(t, err, &unprocessable)
+	assert.Equal(t, entity.FailureInsufficientFunds, unprocessable.Code)
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "GetByIDForUpdate")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToDailyLimitExceeded(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	maxDaily := decimal.NewFromInt(1500)
+	_ = fromAccount.ChangeTransferLimits(entity.TransferLimits{MaxDailyAmount: &maxDaily})
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("SumOutgoing", fromAccount.ID, m.MatchedBy(func(since time.Time) bool {
+		return since.Equal(since.Truncate(24*time.Hour)) && time.Since(since) < 24*time.Hour
+	})).
+		Return(&entity.TransferTotals{Count: 2, Amount: decimal.NewFromInt(600)}, nil)
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	var limitExceeded *entity.LimitExceededError
+	assert.ErrorAs(t, err, &limitExceeded)
+	assert.Equal(t, entity.MaxDailyAmount, limitExceeded.Limit)
+	assert.Equal(t, "1600", limitExceeded.Reached.String())
+	assert.Nil(t, output)
+	assert.Equal(t, "2000", fromAccount.Balance.String())
+
+	transactionGatewayMock.AssertExpectations(t)
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToTransferLimitExceeded(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	maxTransfer := decimal.NewFromInt(500)
+	_ = fromAccount.ChangeTransferLimits(entity.TransferLimits{MaxTransferAmount: &maxTransfer})
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	var limitExceeded *entity.LimitExceededError
+	assert.ErrorAs(t, err, &limitExceeded)
+	assert.Equal(t, entity.MaxTransferAmount, limitExceeded.Limit)
+	assert.Nil(t, output)
+
+	// Without daily limits, the transfers of the day aren't read at all.
+	transactionGatewayMock.AssertNotCalled(t, "SumOutgoing", m.Anything, m.Anything)
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+}
+
+func TestCreateTransactionUseCase_Execute_ConvertBetweenCurrencies(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.USD)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", fromAccount.ID, decimal.NewFromInt(1000), int64(0)).Return(nil)
+	accountGatewayMock.On("UpdateBalance", toAccount.ID, decimal.RequireFromString("193.45"), int64(0)).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("Create", m.MatchedBy(func(transaction *entity.Transaction) bool {
+		return transaction.DestinationAmount.String() == "193.45" && transaction.FXRate.String() == "0.193451"
+	})).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.TransactionCreatedV1)
+		return ok && content.Currency == "BRL" && content.DestinationCurrency == "USD" &&
+			content.DestinationAmount.String() == "193.45"
+	})).Return(nil)
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		return event.Name == AccountBalanceUpdated
+	})).Return(nil)
+
+	fxRateProviderMock := &FXRateProviderMock{}
+	fxRateProviderMock.On("GetRate", entity.BRL, entity.USD).Return(decimal.RequireFromString("0.193451"), nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	useCase.FXRateProvider = fxRateProviderMock
+	useCase.Rounding = entity.RoundDown
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1000", output.Amount.String())
+	assert.Equal(t, entity.BRL, output.Currency)
+	assert.Equal(t, "193.45", output.DestinationAmount.String())
+	assert.Equal(t, entity.USD, output.DestinationCurrency)
+	assert.Equal(t, "0.193451", output.FXRate.String())
+
+	accountGatewayMock.AssertExpectations(t)
+	transactionGatewayMock.AssertExpectations(t)
+	outboxGatewayMock.AssertExpectations(t)
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToFXRateUnavailable(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.JPY)
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	fxRateProviderMock := &FXRateProviderMock{}
+	fxRateProviderMock.On("GetRate", entity.BRL, entity.JPY).Return(decimal.Zero, gateway.ErrFXRateNotFound)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	useCase.FXRateProvider = fxRateProviderMock
+	output, err := useCase.Execute(context.Background(), command)
+
+	var unprocessable *entity.UnprocessableError
+	assert.ErrorAs(t, err, &unprocessable)
+	assert.Equal(t, "fx_rate_unavailable", unprocessable.Code)
+	assert.Nil(t, output)
+	assert.Equal(t, "2000", fromAccount.Balance.String())
+
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestCreateTransactionUseCase_Execute_FailDueToAccountNotFound(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	toAccountID := uuid.New()
+
+	command := CreateTransactionCommand{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccountID,
+		Amount:        decimal.NewFromInt(1000),
+	}
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccountID).Return(&entity.Account{}, sql.ErrNoRows)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything, m.Anything).Return(nil)
+
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), command)
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, output)
+
+	transactionGatewayMock.AssertNotCalled(t, "Create")
 }
 
 func TestCreateTransactionUseCase_Execute_FailDueToErrorOnUnitOfWorkTransaction(t *testing.T) {
@@ -61,14 +654,12 @@ func TestCreateTransactionUseCase_Execute_FailDueToErrorOnUnitOfWorkTransaction(
 		Amount:        decimal.NewFromInt(1000),
 	}
 
-	unitOfWorkMock := &UnitOfWorkMock{}
+	unitOfWorkMock := newUnitOfWorkMock(&AccountGatewayMock{}, &TransactionGatewayMock{}, &OutboxGatewayMock{})
 	unitOfWorkMock.
 		On("Do", m.Anything, m.Anything).
 		Return(errors.New(expectedErrorMessage))
 
-	eventPublisherMock := &EventPublisherMock{}
-
-	useCase := NewCreateTransactionUseCase(unitOfWorkMock, eventPublisherMock)
+	useCase := NewCreateTransactionUseCase(unitOfWorkMock)
 	output, err := useCase.Execute(context.Background(), command)
 
 	assert.NotNil(t, err)
@@ -77,32 +668,157 @@ func TestCreateTransactionUseCase_Execute_FailDueToErrorOnUnitOfWorkTransaction(
 
 	unitOfWorkMock.AssertExpectations(t)
 	unitOfWorkMock.AssertNumberOfCalls(t, "Do", 1)
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	args := m.Called(ID, amount, version)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+type TransactionGatewayMock struct {
+	m.Mock
+}
+
+func (m *TransactionGatewayMock) Create(transaction *entity.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionGatewayMock) GetByID(ID uuid.UUID) (*entity.Transaction, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Transaction), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Transaction), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) Update(transaction *entity.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionGatewayMock) ListByAccount(filter gateway.StatementFilter) ([]*entity.StatementEntry, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error) {
+	args := m.Called(accountID, since)
+	return args.Get(0).(*entity.TransferTotals), args.Error(1)
+}
+
+type LedgerGatewayMock struct {
+	m.Mock
+}
 
-	eventPublisherMock.AssertExpectations(t)
-	eventPublisherMock.AssertNotCalled(t, "Register")
-	eventPublisherMock.AssertNotCalled(t, "Publish")
+func (m *LedgerGatewayMock) Create(entries ...*entity.LedgerEntry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
+
+func (m *LedgerGatewayMock) GetBalances() ([]*entity.LedgerBalance, error) {
+	args := m.Called()
+	return args.Get(0).([]*entity.LedgerBalance), args.Error(1)
 }
 
-type EventPublisherMock struct {
+type IdempotencyGatewayMock struct {
 	m.Mock
 }
 
-func (e *EventPublisherMock) Register(event events.Event) events.EventPublisherInterface {
-	args := e.Called(event)
-	return args.Get(0).(events.EventPublisherInterface)
+func (m *IdempotencyGatewayMock) Create(key *entity.IdempotencyKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *IdempotencyGatewayMock) GetByKey(key string) (*entity.IdempotencyKey, error) {
+	args := m.Called(key)
+	return args.Get(0).(*entity.IdempotencyKey), args.Error(1)
+}
+
+func (m *IdempotencyGatewayMock) Delete(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
 }
 
-func (e *EventPublisherMock) Publish() {
-	e.Called()
+func (m *IdempotencyGatewayMock) DeleteExpired(now time.Time) (int64, error) {
+	panic("implement me")
+}
+
+type OutboxGatewayMock struct {
+	m.Mock
+}
+
+func (m *OutboxGatewayMock) Create(event events.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+type FXRateProviderMock struct {
+	m.Mock
+}
+
+func (m *FXRateProviderMock) GetRate(from entity.Currency, to entity.Currency) (decimal.Decimal, error) {
+	args := m.Called(from, to)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
 type UnitOfWorkMock struct {
 	m.Mock
+	Repositories map[string]interface{}
+}
+
+func newUnitOfWorkMock(
+	accountGateway *AccountGatewayMock,
+	transactionGateway *TransactionGatewayMock,
+	outboxGateway *OutboxGatewayMock,
+) *UnitOfWorkMock {
+	ledgerGateway := &LedgerGatewayMock{}
+	ledgerGateway.On("Create", m.Anything).Return(nil)
+
+	return &UnitOfWorkMock{
+		Repositories: map[string]interface{}{
+			"AccountGateway":     accountGateway,
+			"TransactionGateway": transactionGateway,
+			"LedgerGateway":      ledgerGateway,
+			"OutboxGateway":      outboxGateway,
+		},
+	}
 }
 
-func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork *uow.UnitOfWork) error) error {
+func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork uow.UnitOfWorkInterface) error) error {
 	args := m.Called(fn)
-	return args.Error(0)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
 }
 
 func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
@@ -110,7 +826,7 @@ func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
 func (m *UnitOfWorkMock) Remove(name string) {}
 
 func (m *UnitOfWorkMock) GetRepository(ctx context.Context, name string) (interface{}, error) {
-	return nil, nil
+	return m.Repositories[name], nil
 }
 
 func (m *UnitOfWorkMock) CommitOrRollback() error {
@@ -0,0 +1,91 @@
+package get_account
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type GetAccountQuery struct {
+	ID uuid.UUID
+}
+
+type OwnerOutput struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+}
+
+// TransferLimitsOutput reports lifted limits as null.
+type TransferLimitsOutput struct {
+	MaxTransferAmount *decimal.Decimal `json:"max_transfer_amount"`
+	MaxDailyAmount    *decimal.Decimal `json:"max_daily_amount"`
+	MaxDailyCount     *int64           `json:"max_daily_count"`
+}
+
+type GetAccountOutput struct {
+	ID               uuid.UUID            `json:"id"`
+	Currency         entity.Currency      `json:"currency"`
+	Balance          decimal.Decimal      `json:"balance"`
+	OverdraftLimit   decimal.Decimal      `json:"overdraft_limit"`
+	AvailableBalance decimal.Decimal      `json:"available_balance"`
+	TransferLimits   TransferLimitsOutput `json:"transfer_limits"`
+	Owner            OwnerOutput          `json:"owner"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+type GetAccountUseCase struct {
+	AccountGateway  gateway.AccountGateway
+	CustomerGateway gateway.CustomerGateway
+}
+
+func NewGetAccountUseCase(
+	accountGateway gateway.AccountGateway,
+	customerGateway gateway.CustomerGateway,
+) *GetAccountUseCase {
+	return &GetAccountUseCase{
+		AccountGateway:  accountGateway,
+		CustomerGateway: customerGateway,
+	}
+}
+
+func (uc *GetAccountUseCase) Execute(query GetAccountQuery) (*GetAccountOutput, error) {
+	account, err := uc.AccountGateway.GetByID(query.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// AccountGateway only loads the owner's ID.
+	customer, err := uc.CustomerGateway.GetByID(account.Customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetAccountOutput{
+		ID:               account.ID,
+		Currency:         account.Currency,
+		Balance:          account.Balance,
+		OverdraftLimit:   account.OverdraftLimit,
+		AvailableBalance: account.AvailableBalance(),
+		TransferLimits: TransferLimitsOutput{
+			MaxTransferAmount: account.TransferLimits.MaxTransferAmount,
+			MaxDailyAmount:    account.TransferLimits.MaxDailyAmount,
+			MaxDailyCount:     account.TransferLimits.MaxDailyCount,
+		},
+		Owner: OwnerOutput{
+			ID:    customer.ID,
+			Name:  customer.Name,
+			Email: customer.Email,
+		},
+		CreatedAt: account.CreatedAt,
+		UpdatedAt: account.UpdatedAt,
+	}, nil
+}
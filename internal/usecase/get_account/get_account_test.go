@@ -0,0 +1,109 @@
+package get_account
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestGetAccountUseCase_Execute_GetSuccessfully(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := entity.NewAccount(&entity.Customer{ID: customer.ID}, entity.BRL)
+	_ = account.Credit(decimal.NewFromInt(1000))
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", account.ID).Return(account, nil)
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByID", customer.ID).Return(customer, nil)
+
+	useCase := NewGetAccountUseCase(accountGatewayMock, customerGatewayMock)
+	output, err := useCase.Execute(GetAccountQuery{ID: account.ID})
+
+	assert.Nil(t, err)
+	assert.Equal(t, account.ID, output.ID)
+	assert.Equal(t, "1000", output.Balance.String())
+	assert.Equal(t, customer.ID, output.Owner.ID)
+	assert.Equal(t, customer.Name, output.Owner.Name)
+	assert.Equal(t, customer.Email, output.Owner.Email)
+
+	accountGatewayMock.AssertExpectations(t)
+	customerGatewayMock.AssertExpectations(t)
+}
+
+func TestGetAccountUseCase_Execute_FailDueToAccountNotFound(t *testing.T) {
+	accountID := uuid.New()
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", accountID).Return(&entity.Account{}, sql.ErrNoRows)
+
+	customerGatewayMock := &CustomerGatewayMock{}
+
+	useCase := NewGetAccountUseCase(accountGatewayMock, customerGatewayMock)
+	output, err := useCase.Execute(GetAccountQuery{ID: accountID})
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, output)
+
+	customerGatewayMock.AssertNotCalled(t, "GetByID")
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+type CustomerGatewayMock struct {
+	m.Mock
+}
+
+func (m *CustomerGatewayMock) Create(customer *entity.Customer) error {
+	args := m.Called(customer)
+	return args.Error(0)
+}
+
+func (m *CustomerGatewayMock) GetByID(ID uuid.UUID) (*entity.Customer, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByEmail(email string) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) Update(customer *entity.Customer) error {
+	panic("implement me")
+}
@@ -0,0 +1,199 @@
+package get_account_statement
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = &entity.ValidationError{Field: "cursor", Message: "'cursor' is invalid"}
+
+type GetAccountStatementQuery struct {
+	AccountID uuid.UUID
+	// Direction is either "incoming", "outgoing" or empty for both.
+	Direction entity.TransferDirection
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+// StatementEntryOutput reports Amount in the account currency: the debited
+// amount of outgoing transactions and the credited one of incoming ones.
+type StatementEntryOutput struct {
+	TransactionID  uuid.UUID                `json:"transaction_id"`
+	FromAccountID  uuid.UUID                `json:"from_account_id"`
+	ToAccountID    uuid.UUID                `json:"to_account_id"`
+	Direction      entity.TransferDirection `json:"direction"`
+	Amount         decimal.Decimal          `json:"amount"`
+	Status         entity.Status            `json:"status"`
+	RunningBalance decimal.Decimal          `json:"running_balance"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+type GetAccountStatementOutput struct {
+	AccountID  uuid.UUID              `json:"account_id"`
+	Entries    []StatementEntryOutput `json:"entries"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+type GetAccountStatementUseCase struct {
+	AccountGateway     gateway.AccountGateway
+	TransactionGateway gateway.TransactionGateway
+}
+
+func NewGetAccountStatementUseCase(
+	accountGateway gateway.AccountGateway,
+	transactionGateway gateway.TransactionGateway,
+) *GetAccountStatementUseCase {
+	return &GetAccountStatementUseCase{
+		AccountGateway:     accountGateway,
+		TransactionGateway: transactionGateway,
+	}
+}
+
+func (uc *GetAccountStatementUseCase) Execute(query GetAccountStatementQuery) (*GetAccountStatementOutput, error) {
+	filter, err := newStatementFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.AccountGateway.GetByID(query.AccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra entry tells whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := uc.TransactionGateway.ListByAccount(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetAccountStatementOutput{
+		AccountID: query.AccountID,
+		Entries:   []StatementEntryOutput{},
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		output.NextCursor = encodeCursor(entries[limit-1].Transaction)
+	}
+	for _, entry := range entries {
+		amount := entry.Transaction.Amount
+		if entry.Direction == entity.Incoming {
+			amount = entry.Transaction.DestinationAmount
+		}
+		output.Entries = append(output.Entries, StatementEntryOutput{
+			TransactionID:  entry.Transaction.ID,
+			FromAccountID:  entry.Transaction.FromAccount.ID,
+			ToAccountID:    entry.Transaction.ToAccount.ID,
+			Direction:      entry.Direction,
+			Amount:         amount,
+			Status:         entry.Transaction.Status,
+			RunningBalance: entry.RunningBalance,
+			CreatedAt:      entry.Transaction.CreatedAt,
+		})
+	}
+
+	return output, nil
+}
+
+func newStatementFilter(query GetAccountStatementQuery) (gateway.StatementFilter, error) {
+	filter := gateway.StatementFilter{
+		AccountID: query.AccountID,
+		Direction: query.Direction,
+		From:      utc(query.From),
+		To:        utc(query.To),
+		Limit:     query.Limit,
+	}
+
+	switch query.Direction {
+	case "", entity.Incoming, entity.Outgoing:
+	default:
+		return filter, &entity.ValidationError{
+			Field:   "direction",
+			Message: fmt.Sprintf("'direction' must be %q or %q", entity.Incoming, entity.Outgoing),
+		}
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return filter, &entity.ValidationError{Field: "from", Message: "'from' must be before 'to'"}
+	}
+
+	if query.Limit < 0 || query.Limit > MaxLimit {
+		return filter, &entity.ValidationError{
+			Field:   "limit",
+			Message: fmt.Sprintf("'limit' must be between 1 and %d", MaxLimit),
+		}
+	}
+	if query.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+// utc brings a bound to UTC, as created_at is stored: the column has no time
+// zone, so an offset left on the bound would be dropped rather than applied.
+func utc(bound *time.Time) *time.Time {
+	if bound == nil {
+		return nil
+	}
+	converted := bound.UTC()
+	return &converted
+}
+
+// The cursor is opaque to clients: the creation time and ID of the last
+// transaction they received.
+func encodeCursor(transaction *entity.Transaction) string {
+	value := transaction.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + transaction.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func decodeCursor(cursor string) (*gateway.StatementCursor, error) {
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, ID, found := strings.Cut(string(value), ",")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	var statementCursor gateway.StatementCursor
+	statementCursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	statementCursor.ID, err = uuid.Parse(ID)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &statementCursor, nil
+}
@@ -0,0 +1,216 @@
+package get_account_statement
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestGetAccountStatementUseCase_Execute_ReturnNextCursor(t *testing.T) {
+	account, entries := newStatement(3)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", account.ID).Return(account, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("ListByAccount", gateway.StatementFilter{
+		AccountID: account.ID,
+		Limit:     3,
+	}).Return(entries, nil)
+
+	useCase := NewGetAccountStatementUseCase(accountGatewayMock, transactionGatewayMock)
+	output, err := useCase.Execute(GetAccountStatementQuery{AccountID: account.ID, Limit: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, account.ID, output.AccountID)
+	assert.Len(t, output.Entries, 2)
+	assert.Equal(t, entries[0].Transaction.ID, output.Entries[0].TransactionID)
+	assert.Equal(t, entity.Outgoing, output.Entries[0].Direction)
+	assert.Equal(t, entries[0].RunningBalance, output.Entries[0].RunningBalance)
+	assert.NotEmpty(t, output.NextCursor)
+
+	cursor, err := decodeCursor(output.NextCursor)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entries[1].Transaction.ID, cursor.ID)
+	assert.True(t, entries[1].Transaction.CreatedAt.Equal(cursor.CreatedAt))
+
+	transactionGatewayMock.AssertExpectations(t)
+}
+
+func TestGetAccountStatementUseCase_Execute_PassCursorToGateway(t *testing.T) {
+	account, entries := newStatement(2)
+	last := entries[1].Transaction
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", account.ID).Return(account, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("ListByAccount", m.MatchedBy(func(filter gateway.StatementFilter) bool {
+		return filter.After != nil &&
+			filter.After.ID == last.ID &&
+			filter.After.CreatedAt.Equal(last.CreatedAt) &&
+			filter.Direction == entity.Outgoing &&
+			filter.Limit == DefaultLimit+1
+	})).Return([]*entity.StatementEntry{}, nil)
+
+	useCase := NewGetAccountStatementUseCase(accountGatewayMock, transactionGatewayMock)
+	output, err := useCase.Execute(GetAccountStatementQuery{
+		AccountID: account.ID,
+		Direction: entity.Outgoing,
+		Cursor:    encodeCursor(last),
+	})
+
+	assert.Nil(t, err)
+	assert.Empty(t, output.Entries)
+	assert.Empty(t, output.NextCursor)
+
+	transactionGatewayMock.AssertExpectations(t)
+}
+
+func TestGetAccountStatementUseCase_Execute_PassDateBoundsInUTC(t *testing.T) {
+	account, _ := newStatement(0)
+	offset := time.FixedZone("UTC+2", 2*60*60)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, offset)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, offset)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", account.ID).Return(account, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("ListByAccount", m.MatchedBy(func(filter gateway.StatementFilter) bool {
+		return filter.From.Location() == time.UTC &&
+			filter.From.Equal(time.Date(2023, 12, 31, 22, 0, 0, 0, time.UTC)) &&
+			filter.To.Location() == time.UTC &&
+			filter.To.Equal(time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC))
+	})).Return([]*entity.StatementEntry{}, nil)
+
+	useCase := NewGetAccountStatementUseCase(accountGatewayMock, transactionGatewayMock)
+	_, err := useCase.Execute(GetAccountStatementQuery{AccountID: account.ID, From: &from, To: &to})
+
+	assert.Nil(t, err)
+	transactionGatewayMock.AssertExpectations(t)
+}
+
+func TestGetAccountStatementUseCase_Execute_FailDueToInvalidQuery(t *testing.T) {
+	accountID := uuid.New()
+	accountGatewayMock := &AccountGatewayMock{}
+	transactionGatewayMock := &TransactionGatewayMock{}
+	useCase := NewGetAccountStatementUseCase(accountGatewayMock, transactionGatewayMock)
+
+	for expectedErrorMessage, query := range map[string]GetAccountStatementQuery{
+		"'direction' must be \"incoming\" or \"outgoing\"": {AccountID: accountID, Direction: "sideways"},
+		"'limit' must be between 1 and 100":                {AccountID: accountID, Limit: 101},
+		"'cursor' is invalid":                              {AccountID: accountID, Cursor: "not a cursor"},
+	} {
+		output, err := useCase.Execute(query)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, expectedErrorMessage, err.Error())
+		assert.Nil(t, output)
+	}
+
+	accountGatewayMock.AssertNotCalled(t, "GetByID")
+	transactionGatewayMock.AssertNotCalled(t, "ListByAccount")
+}
+
+func TestGetAccountStatementUseCase_Execute_FailDueToAccountNotFound(t *testing.T) {
+	accountID := uuid.New()
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", accountID).Return(&entity.Account{}, sql.ErrNoRows)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+
+	useCase := NewGetAccountStatementUseCase(accountGatewayMock, transactionGatewayMock)
+	output, err := useCase.Execute(GetAccountStatementQuery{AccountID: accountID})
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, output)
+
+	transactionGatewayMock.AssertNotCalled(t, "ListByAccount")
+}
+
+// newStatement returns count outgoing entries of an account, newest first.
+func newStatement(count int) (*entity.Account, []*entity.StatementEntry) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	account, _ := entity.NewAccount(customer, entity.BRL)
+	_ = account.Credit(decimal.NewFromInt(1000))
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+
+	entries := make([]*entity.StatementEntry, count)
+	for i := count - 1; i >= 0; i-- {
+		transaction, _ := entity.NewTransaction(account, toAccount, decimal.NewFromInt(100), nil)
+		entries[i] = &entity.StatementEntry{
+			Transaction:    transaction,
+			Direction:      entity.Outgoing,
+			RunningBalance: account.Balance,
+		}
+	}
+	return account, entries
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+type TransactionGatewayMock struct {
+	m.Mock
+}
+
+func (m *TransactionGatewayMock) Create(transaction *entity.Transaction) error {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) GetByID(ID uuid.UUID) (*entity.Transaction, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) Update(transaction *entity.Transaction) error {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) ListByAccount(filter gateway.StatementFilter) ([]*entity.StatementEntry, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]*entity.StatementEntry), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error) {
+	panic("implement me")
+}
@@ -0,0 +1,57 @@
+package get_customer
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"time"
+)
+
+// GetCustomerQuery looks a customer up by ID or, when ID is uuid.Nil, by
+// Email.
+type GetCustomerQuery struct {
+	ID    uuid.UUID
+	Email string
+}
+
+type GetCustomerOutput struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type GetCustomerUseCase struct {
+	CustomerGateway gateway.CustomerGateway
+}
+
+func NewGetCustomerUseCase(customerGateway gateway.CustomerGateway) *GetCustomerUseCase {
+	return &GetCustomerUseCase{CustomerGateway: customerGateway}
+}
+
+func (uc *GetCustomerUseCase) Execute(query GetCustomerQuery) (*GetCustomerOutput, error) {
+	var customer *entity.Customer
+	var err error
+	if query.ID == uuid.Nil && query.Email != "" {
+		customer, err = uc.CustomerGateway.GetByEmail(query.Email)
+	} else {
+		customer, err = uc.CustomerGateway.GetByID(query.ID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetCustomerOutput{
+		ID:        customer.ID,
+		Name:      customer.Name,
+		Email:     customer.Email,
+		CreatedAt: customer.CreatedAt,
+		UpdatedAt: customer.UpdatedAt,
+	}, nil
+}
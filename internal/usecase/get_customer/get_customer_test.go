@@ -0,0 +1,106 @@
+package get_customer
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestGetCustomerUseCase_Execute_GetSuccessfully(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByID", customer.ID).Return(customer, nil)
+
+	useCase := NewGetCustomerUseCase(customerGatewayMock)
+	output, err := useCase.Execute(GetCustomerQuery{ID: customer.ID})
+
+	assert.Nil(t, err)
+	assert.Equal(t, customer.ID, output.ID)
+	assert.Equal(t, customer.Name, output.Name)
+	assert.Equal(t, customer.Email, output.Email)
+	assert.Equal(t, customer.CreatedAt, output.CreatedAt)
+	assert.Equal(t, customer.UpdatedAt, output.UpdatedAt)
+
+	customerGatewayMock.AssertExpectations(t)
+}
+
+func TestGetCustomerUseCase_Execute_GetByEmailSuccessfully(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByEmail", "AlexandreBrunoDias@gmail.com").Return(customer, nil)
+
+	useCase := NewGetCustomerUseCase(customerGatewayMock)
+	output, err := useCase.Execute(GetCustomerQuery{Email: "AlexandreBrunoDias@gmail.com"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, customer.ID, output.ID)
+	customerGatewayMock.AssertNotCalled(t, "GetByID", m.Anything)
+}
+
+func TestGetCustomerUseCase_Execute_FailDueToEmailNotFound(t *testing.T) {
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByEmail", "nobody@gmail.com").Return((*entity.Customer)(nil), sql.ErrNoRows)
+
+	useCase := NewGetCustomerUseCase(customerGatewayMock)
+	output, err := useCase.Execute(GetCustomerQuery{Email: "nobody@gmail.com"})
+
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	assert.Nil(t, output)
+}
+
+func TestGetCustomerUseCase_Execute_FailDueToCustomerNotFound(t *testing.T) {
+	customerID := uuid.New()
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByID", customerID).Return(&entity.Customer{}, sql.ErrNoRows)
+
+	useCase := NewGetCustomerUseCase(customerGatewayMock)
+	output, err := useCase.Execute(GetCustomerQuery{ID: customerID})
+
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	assert.Nil(t, output)
+}
+
+func TestGetCustomerUseCase_Execute_FailDueToGatewayError(t *testing.T) {
+	customerID := uuid.New()
+	expectedErrorMessage := "gateway error"
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByID", customerID).Return(&entity.Customer{}, errors.New(expectedErrorMessage))
+
+	useCase := NewGetCustomerUseCase(customerGatewayMock)
+	output, err := useCase.Execute(GetCustomerQuery{ID: customerID})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.Nil(t, output)
+}
+
+type CustomerGatewayMock struct {
+	m.Mock
+}
+
+func (m *CustomerGatewayMock) Create(customer *entity.Customer) error {
+	args := m.Called(customer)
+	return args.Error(0)
+}
+
+func (m *CustomerGatewayMock) GetByID(ID uuid.UUID) (*entity.Customer, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByEmail(email string) (*entity.Customer, error) {
+	args := m.Called(email)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) Update(customer *entity.Customer) error {
+	panic("implement me")
+}
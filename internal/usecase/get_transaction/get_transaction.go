@@ -0,0 +1,80 @@
+package get_transaction
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/transfer"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type GetTransactionQuery struct {
+	ID uuid.UUID
+}
+
+// GetTransactionOutput reports Amount in the currency of the debited account
+// and DestinationAmount in the one of the credited account.
+type GetTransactionOutput struct {
+	ID                  uuid.UUID        `json:"id"`
+	FromAccountID       uuid.UUID        `json:"from_account_id"`
+	ToAccountID         uuid.UUID        `json:"to_account_id"`
+	Amount              decimal.Decimal  `json:"amount"`
+	Currency            entity.Currency  `json:"currency"`
+	DestinationAmount   decimal.Decimal  `json:"destination_amount"`
+	DestinationCurrency entity.Currency  `json:"destination_currency"`
+	FXRate              *decimal.Decimal `json:"fx_rate,omitempty"`
+	Status              entity.Status    `json:"status"`
+	RefundedAmount      decimal.Decimal  `json:"refunded_amount"`
+	ReversalOf          *uuid.UUID       `json:"reversal_of,omitempty"`
+	FailureReason       string           `json:"failure_reason,omitempty"`
+	CreatedAt           time.Time        `json:"created_at"`
+}
+
+type GetTransactionUseCase struct {
+	AccountGateway     gateway.AccountGateway
+	TransactionGateway gateway.TransactionGateway
+}
+
+func NewGetTransactionUseCase(
+	accountGateway gateway.AccountGateway,
+	transactionGateway gateway.TransactionGateway,
+) *GetTransactionUseCase {
+	return &GetTransactionUseCase{
+		AccountGateway:     accountGateway,
+		TransactionGateway: transactionGateway,
+	}
+}
+
+func (uc *GetTransactionUseCase) Execute(query GetTransactionQuery) (*GetTransactionOutput, error) {
+	transaction, err := uc.TransactionGateway.GetByID(query.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := transfer.LoadAccounts(uc.AccountGateway.GetByID, transaction.FromAccount.ID, transaction.ToAccount.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetTransactionOutput{
+		ID:                  transaction.ID,
+		FromAccountID:       transaction.FromAccount.ID,
+		ToAccountID:         transaction.ToAccount.ID,
+		Amount:              transaction.Amount,
+		Currency:            accounts[transaction.FromAccount.ID].Currency,
+		DestinationAmount:   transaction.DestinationAmount,
+		DestinationCurrency: accounts[transaction.ToAccount.ID].Currency,
+		FXRate:              transaction.FXRate,
+		Status:              transaction.Status,
+		RefundedAmount:      transaction.RefundedAmount,
+		ReversalOf:          transaction.ReversalOf,
+		FailureReason:       transaction.FailureReason,
+		CreatedAt:           transaction.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package get_transaction
+
+import (
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGetTransactionUseCase_Execute_GetSuccessfully(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(1000))
+	toAccount, _ := entity.NewAccount(customer, entity.USD)
+	conversion := &entity.Conversion{Rate: decimal.RequireFromString("0.2"), Rounding: entity.RoundHalfEven}
+	transaction, _ := entity.NewTransaction(fromAccount, toAccount, decimal.NewFromInt(400), conversion)
+	_ = transaction.Commit()
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByID", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByID", transaction.ID).Return(transaction, nil)
+
+	useCase := NewGetTransactionUseCase(accountGatewayMock, transactionGatewayMock)
+	output, err := useCase.Execute(GetTransactionQuery{ID: transaction.ID})
+
+	assert.Nil(t, err)
+	assert.Equal(t, transaction.ID, output.ID)
+	assert.Equal(t, fromAccount.ID, output.FromAccountID)
+	assert.Equal(t, toAccount.ID, output.ToAccountID)
+	assert.Equal(t, "400", output.Amount.String())
+	assert.Equal(t, entity.BRL, output.Currency)
+	assert.Equal(t, "80", output.DestinationAmount.String())
+	assert.Equal(t, entity.USD, output.DestinationCurrency)
+	assert.Equal(t, entity.Completed, output.Status)
+	assert.Nil(t, output.ReversalOf)
+
+	accountGatewayMock.AssertExpectations(t)
+	transactionGatewayMock.AssertExpectations(t)
+}
+
+func TestGetTransactionUseCase_Execute_ReturnFailureReasonCode(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_, err := entity.NewTransaction(fromAccount, toAccount, decimal.NewFromInt(400), nil)
+	var failed *entity.TransactionFailedError
+	require.ErrorAs(t, err, &failed)
+	transaction := failed.Transaction
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByID", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByID", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByID", transaction.ID).Return(transaction, nil)
+
+	useCase := NewGetTransactionUseCase(accountGatewayMock, transactionGatewayMock)
+	output, err := useCase.Execute(GetTransactionQuery{ID: transaction.ID})
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Failed, output.Status)
+	assert.Equal(t, entity.FailureInsufficientFunds, output.FailureReason)
+}
+
+func TestGetTransactionUseCase_Execute_FailDueToTransactionNotFound(t *testing.T) {
+	transactionID := uuid.New()
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByID", transactionID).Return(&entity.Transaction{}, sql.ErrNoRows)
+
+	useCase := NewGetTransactionUseCase(&AccountGatewayMock{}, transactionGatewayMock)
+	output, err := useCase.Execute(GetTransactionQuery{ID: transactionID})
+
+	assert.ErrorIs(t, err, entity.ErrTransactionNotFound)
+	assert.Nil(t, output)
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+type TransactionGatewayMock struct {
+	m.Mock
+}
+
+func (m *TransactionGatewayMock) Create(transaction *entity.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionGatewayMock) GetByID(ID uuid.UUID) (*entity.Transaction, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Transaction), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) Update(transaction *entity.Transaction) error {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) ListByAccount(filter gateway.StatementFilter) ([]*entity.StatementEntry, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error) {
+	panic("implement me")
+}
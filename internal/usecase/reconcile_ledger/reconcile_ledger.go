@@ -0,0 +1,57 @@
+package reconcile_ledger
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type AccountDriftOutput struct {
+	AccountID     uuid.UUID       `json:"account_id"`
+	Balance       decimal.Decimal `json:"balance"`
+	LedgerBalance decimal.Decimal `json:"ledger_balance"`
+	Drift         decimal.Decimal `json:"drift"`
+}
+
+type ReconcileLedgerOutput struct {
+	CheckedAccounts int                  `json:"checked_accounts"`
+	Drifts          []AccountDriftOutput `json:"drifts"`
+}
+
+// ReconcileLedgerUseCase recomputes every account balance from its ledger
+// entries and reports the accounts whose stored balance disagrees.
+type ReconcileLedgerUseCase struct {
+	LedgerGateway gateway.LedgerGateway
+}
+
+func NewReconcileLedgerUseCase(ledgerGateway gateway.LedgerGateway) *ReconcileLedgerUseCase {
+	return &ReconcileLedgerUseCase{
+		LedgerGateway: ledgerGateway,
+	}
+}
+
+func (uc *ReconcileLedgerUseCase) Execute() (*ReconcileLedgerOutput, error) {
+	balances, err := uc.LedgerGateway.GetBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ReconcileLedgerOutput{
+		CheckedAccounts: len(balances),
+		Drifts:          []AccountDriftOutput{},
+	}
+	for _, balance := range balances {
+		drift := balance.Drift()
+		if drift.IsZero() {
+			continue
+		}
+		output.Drifts = append(output.Drifts, AccountDriftOutput{
+			AccountID:     balance.AccountID,
+			Balance:       balance.Balance,
+			LedgerBalance: balance.LedgerBalance,
+			Drift:         drift,
+		})
+	}
+
+	return output, nil
+}
@@ -0,0 +1,61 @@
+package reconcile_ledger
+
+import (
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestReconcileLedgerUseCase_Execute_ReportOnlyDriftingAccounts(t *testing.T) {
+	driftingAccountID := uuid.New()
+	balances := []*entity.LedgerBalance{
+		{AccountID: uuid.New(), Balance: decimal.NewFromInt(100), LedgerBalance: decimal.NewFromInt(100)},
+		{AccountID: driftingAccountID, Balance: decimal.NewFromInt(150), LedgerBalance: decimal.NewFromInt(100)},
+	}
+
+	ledgerGatewayMock := &LedgerGatewayMock{}
+	ledgerGatewayMock.On("GetBalances").Return(balances, nil)
+
+	useCase := NewReconcileLedgerUseCase(ledgerGatewayMock)
+	output, err := useCase.Execute()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, output.CheckedAccounts)
+	assert.Len(t, output.Drifts, 1)
+	assert.Equal(t, driftingAccountID, output.Drifts[0].AccountID)
+	assert.Equal(t, "50", output.Drifts[0].Drift.String())
+
+	ledgerGatewayMock.AssertExpectations(t)
+}
+
+func TestReconcileLedgerUseCase_Execute_FailDueToGatewayError(t *testing.T) {
+	expectedErrorMessage := "gateway error"
+
+	ledgerGatewayMock := &LedgerGatewayMock{}
+	ledgerGatewayMock.On("GetBalances").Return([]*entity.LedgerBalance{}, errors.New(expectedErrorMessage))
+
+	useCase := NewReconcileLedgerUseCase(ledgerGatewayMock)
+	output, err := useCase.Execute()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.Nil(t, output)
+}
+
+type LedgerGatewayMock struct {
+	m.Mock
+}
+
+func (m *LedgerGatewayMock) Create(entries ...*entity.LedgerEntry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
+
+func (m *LedgerGatewayMock) GetBalances() ([]*entity.LedgerBalance, error) {
+	args := m.Called()
+	return args.Get(0).([]*entity.LedgerBalance), args.Error(1)
+}
@@ -0,0 +1,189 @@
+package reverse_transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/transfer"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TransactionReversed   = wallet.TransactionReversed
+	AccountBalanceUpdated = wallet.AccountBalanceUpdated
+)
+
+type ReverseTransactionCommand struct {
+	TransactionID uuid.UUID `json:"-"`
+	// Amount is in the currency of the original transaction's debited
+	// account and defaults to everything not reversed yet when omitted.
+	Amount *decimal.Decimal `json:"amount"`
+}
+
+type ReverseTransactionOutput struct {
+	ID                      uuid.UUID        `json:"id"`
+	OriginalTransactionID   uuid.UUID        `json:"original_transaction_id"`
+	FromAccountID           uuid.UUID        `json:"from_account_id"`
+	ToAccountID             uuid.UUID        `json:"to_account_id"`
+	Amount                  decimal.Decimal  `json:"amount"`
+	Currency                entity.Currency  `json:"currency"`
+	DestinationAmount       decimal.Decimal  `json:"destination_amount"`
+	DestinationCurrency     entity.Currency  `json:"destination_currency"`
+	FXRate                  *decimal.Decimal `json:"fx_rate,omitempty"`
+	Status                  entity.Status    `json:"status"`
+	OriginalStatus          entity.Status    `json:"original_status"`
+	OriginalRemainingAmount decimal.Decimal  `json:"original_remaining_amount"`
+}
+
+type ReverseTransactionUseCase struct {
+	UnitOfWork uow.UnitOfWorkInterface
+}
+
+func NewReverseTransactionUseCase(unitOfWork uow.UnitOfWorkInterface) *ReverseTransactionUseCase {
+	return &ReverseTransactionUseCase{
+		UnitOfWork: unitOfWork,
+	}
+}
+
+func (uc *ReverseTransactionUseCase) Execute(ctx context.Context, command ReverseTransactionCommand) (*ReverseTransactionOutput, error) {
+	output := &ReverseTransactionOutput{}
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		accountGateway := getAccountGateway(ctx, unitOfWork)
+		transactionGateway := getTransactionGateway(ctx, unitOfWork)
+		ledgerGateway := getLedgerGateway(ctx, unitOfWork)
+		outboxGateway := getOutboxGateway(ctx, unitOfWork)
+
+		// The original stays locked until commit, so concurrent partial
+		// reversals can't refund more than it moved.
+		original, err := transactionGateway.GetByIDForUpdate(command.TransactionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Locked in the same order transfers use, so a reversal and a
+		// transfer can't deadlock.
+		accounts, err := transfer.LoadAccounts(accountGateway.GetByIDForUpdate, original.FromAccount.ID, original.ToAccount.ID)
+		if err != nil {
+			return err
+		}
+		original.FromAccount = accounts[original.FromAccount.ID]
+		original.ToAccount = accounts[original.ToAccount.ID]
+
+		amount := original.RemainingAmount()
+		if command.Amount != nil {
+			amount = *command.Amount
+		}
+
+		reversal, err := entity.NewReversal(original, amount)
+		if err != nil {
+			return err
+		}
+
+		err = transfer.UpdateBalance(accountGateway, reversal.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		err = transfer.UpdateBalance(accountGateway, reversal.ToAccount)
+		if err != nil {
+			return err
+		}
+
+		err = transactionGateway.Create(reversal)
+		if err != nil {
+			return err
+		}
+
+		err = transactionGateway.Update(original)
+		if err != nil {
+			return err
+		}
+
+		err = ledgerGateway.Create(reversal.LedgerEntries()...)
+		if err != nil {
+			return err
+		}
+
+		output.ID = reversal.ID
+		output.OriginalTransactionID = original.ID
+		output.FromAccountID = reversal.FromAccount.ID
+		output.ToAccountID = reversal.ToAccount.ID
+		output.Amount = reversal.Amount
+		output.Currency = reversal.FromAccount.Currency
+		output.DestinationAmount = reversal.DestinationAmount
+		output.DestinationCurrency = reversal.ToAccount.Currency
+		output.FXRate = reversal.FXRate
+		output.Status = reversal.Status
+		output.OriginalStatus = original.Status
+		output.OriginalRemainingAmount = original.RemainingAmount()
+
+		// Keyed like the original transfer's event, so consumers see the
+		// reversal after it.
+		event := events.NewPayloadEvent(wallet.TransactionReversedV1{
+			ID:                      output.ID,
+			OriginalTransactionID:   output.OriginalTransactionID,
+			FromAccountID:           output.FromAccountID,
+			ToAccountID:             output.ToAccountID,
+			Amount:                  output.Amount,
+			Currency:                string(output.Currency),
+			DestinationAmount:       &output.DestinationAmount,
+			DestinationCurrency:     string(output.DestinationCurrency),
+			FXRate:                  output.FXRate,
+			Status:                  string(output.Status),
+			OriginalStatus:          string(output.OriginalStatus),
+			OriginalRemainingAmount: output.OriginalRemainingAmount,
+		}).WithKey(original.FromAccount.ID.String())
+		err = outboxGateway.Create(*event)
+		if err != nil {
+			return err
+		}
+
+		return outboxGateway.Create(*transfer.NewBalanceUpdatedEvent(reversal, original.FromAccount.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func getAccountGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AccountGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AccountGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.AccountGateway)
+}
+
+func getTransactionGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.TransactionGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "TransactionGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.TransactionGateway)
+}
+
+func getLedgerGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.LedgerGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "LedgerGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.LedgerGateway)
+}
+
+func getOutboxGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.OutboxGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "OutboxGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.OutboxGateway)
+}
@@ -0,0 +1,340 @@
+package reverse_transaction
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestReverseTransactionUseCase_Execute_ReverseRemainingAmount(t *testing.T) {
+	fromAccount, toAccount, original := newCompletedTransaction(decimal.NewFromInt(1000))
+	storedOriginal := storedCopy(original)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", toAccount.ID, m.Anything, toAccount.Version).Return(nil)
+	accountGatewayMock.On("UpdateBalance", fromAccount.ID, m.Anything, fromAccount.Version).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByIDForUpdate", original.ID).Return(storedOriginal, nil)
+	transactionGatewayMock.On("Create", m.MatchedBy(func(reversal *entity.Transaction) bool {
+		return *reversal.ReversalOf == original.ID
+	})).Return(nil)
+	transactionGatewayMock.On("Update", m.MatchedBy(func(transaction *entity.Transaction) bool {
+		return transaction.ID == original.ID && transaction.Status == entity.Reversed
+	})).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.TransactionReversedV1)
+		return event.Name == TransactionReversed && event.SchemaVersion == 1 &&
+			event.Key == fromAccount.ID.String() &&
+			ok && content.FromAccountID == toAccount.ID
+	})).Return(nil)
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.AccountBalanceUpdatedV1)
+		return event.Name == AccountBalanceUpdated && event.Key == fromAccount.ID.String() && ok &&
+			content.FromAccountID == toAccount.ID && content.FromAccountBalance.Equal(toAccount.Balance) &&
+			content.ToAccountID == fromAccount.ID && content.ToAccountBalance.Equal(fromAccount.Balance)
+	})).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewReverseTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), ReverseTransactionCommand{TransactionID: original.ID})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, output)
+	assert.Equal(t, original.ID, output.OriginalTransactionID)
+	assert.Equal(t, toAccount.ID, output.FromAccountID)
+	assert.Equal(t, fromAccount.ID, output.ToAccountID)
+	assert.Equal(t, "1000", output.Amount.String())
+	assert.Equal(t, entity.Completed, output.Status)
+	assert.Equal(t, entity.Reversed, output.OriginalStatus)
+	assert.True(t, output.OriginalRemainingAmount.IsZero())
+	assert.Equal(t, "2000", fromAccount.Balance.String())
+	assert.Equal(t, "0", toAccount.Balance.String())
+
+	accountGatewayMock.AssertExpectations(t)
+	transactionGatewayMock.AssertExpectations(t)
+	outboxGatewayMock.AssertExpectations(t)
+}
+
+func TestReverseTransactionUseCase_Execute_ReversePartially(t *testing.T) {
+	fromAccount, toAccount, original := newCompletedTransaction(decimal.NewFromInt(1000))
+	amount := decimal.NewFromInt(250)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+	accountGatewayMock.On("UpdateBalance", m.Anything, m.Anything, m.Anything).Return(nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByIDForUpdate", original.ID).Return(storedCopy(original), nil)
+	transactionGatewayMock.On("Create", m.Anything).Return(nil)
+	transactionGatewayMock.On("Update", m.Anything).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.Anything).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewReverseTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), ReverseTransactionCommand{
+		TransactionID: original.ID,
+		Amount:        &amount,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "250", output.Amount.String())
+	assert.Equal(t, entity.Reversed, output.OriginalStatus)
+	assert.Equal(t, "750", output.OriginalRemainingAmount.String())
+}
+
+func TestReverseTransactionUseCase_Execute_FailDueToAmountAboveRemaining(t *testing.T) {
+	fromAccount, toAccount, original := newCompletedTransaction(decimal.NewFromInt(1000))
+	amount := decimal.NewFromInt(1001)
+	expectedErrorMessage := "reversal amount 1001 exceeds the remaining amount 1000"
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByIDForUpdate", original.ID).Return(storedCopy(original), nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewReverseTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), ReverseTransactionCommand{
+		TransactionID: original.ID,
+		Amount:        &amount,
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestReverseTransactionUseCase_Execute_FailDueToAlreadyFullyReversed(t *testing.T) {
+	fromAccount, toAccount, original := newCompletedTransaction(decimal.NewFromInt(1000))
+	storedOriginal := storedCopy(original)
+	storedOriginal.Status = entity.Reversed
+	storedOriginal.RefundedAmount = original.Amount
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", fromAccount.ID).Return(fromAccount, nil)
+	accountGatewayMock.On("GetByIDForUpdate", toAccount.ID).Return(toAccount, nil)
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByIDForUpdate", original.ID).Return(storedOriginal, nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewReverseTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), ReverseTransactionCommand{TransactionID: original.ID})
+
+	var conflict *entity.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "already_fully_reversed", conflict.Code)
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "UpdateBalance")
+	transactionGatewayMock.AssertNotCalled(t, "Create")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestReverseTransactionUseCase_Execute_FailDueToTransactionNotFound(t *testing.T) {
+	transactionID := uuid.New()
+
+	accountGatewayMock := &AccountGatewayMock{}
+
+	transactionGatewayMock := &TransactionGatewayMock{}
+	transactionGatewayMock.On("GetByIDForUpdate", transactionID).Return(&entity.Transaction{}, sql.ErrNoRows)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, transactionGatewayMock, &OutboxGatewayMock{})
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewReverseTransactionUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), ReverseTransactionCommand{TransactionID: transactionID})
+
+	assert.ErrorIs(t, err, entity.ErrTransactionNotFound)
+	assert.Nil(t, output)
+
+	accountGatewayMock.AssertNotCalled(t, "GetByIDForUpdate")
+}
+
+func newCompletedTransaction(amount decimal.Decimal) (*entity.Account, *entity.Account, *entity.Transaction) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(2000))
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+	transaction, _ := entity.NewTransaction(fromAccount, toAccount, amount, nil)
+	return fromAccount, toAccount, transaction
+}
+
+// storedCopy mirrors what TransactionGateway returns: accounts carry only
+// their IDs.
+func storedCopy(transaction *entity.Transaction) *entity.Transaction {
+	stored := *transaction
+	stored.FromAccount = &entity.Account{ID: transaction.FromAccount.ID}
+	stored.ToAccount = &entity.Account{ID: transaction.ToAccount.ID}
+	return &stored
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	args := m.Called(ID, amount, version)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+type TransactionGatewayMock struct {
+	m.Mock
+}
+
+func (m *TransactionGatewayMock) Create(transaction *entity.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionGatewayMock) GetByID(ID uuid.UUID) (*entity.Transaction, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Transaction), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Transaction, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Transaction), args.Error(1)
+}
+
+func (m *TransactionGatewayMock) Update(transaction *entity.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionGatewayMock) ListByAccount(filter gateway.StatementFilter) ([]*entity.StatementEntry, error) {
+	panic("implement me")
+}
+
+func (m *TransactionGatewayMock) SumOutgoing(accountID uuid.UUID, since time.Time) (*entity.TransferTotals, error) {
+	panic("implement me")
+}
+
+type LedgerGatewayMock struct {
+	m.Mock
+}
+
+func (m *LedgerGatewayMock) Create(entries ...*entity.LedgerEntry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
+
+func (m *LedgerGatewayMock) GetBalances() ([]*entity.LedgerBalance, error) {
+	args := m.Called()
+	return args.Get(0).([]*entity.LedgerBalance), args.Error(1)
+}
+
+type OutboxGatewayMock struct {
+	m.Mock
+}
+
+func (m *OutboxGatewayMock) Create(event events.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+type UnitOfWorkMock struct {
+	m.Mock
+	Repositories map[string]interface{}
+}
+
+func newUnitOfWorkMock(
+	accountGateway *AccountGatewayMock,
+	transactionGateway *TransactionGatewayMock,
+	outboxGateway *OutboxGatewayMock,
+) *UnitOfWorkMock {
+	ledgerGateway := &LedgerGatewayMock{}
+	ledgerGateway.On("Create", m.Anything).Return(nil)
+
+	return &UnitOfWorkMock{
+		Repositories: map[string]interface{}{
+			"AccountGateway":     accountGateway,
+			"TransactionGateway": transactionGateway,
+			"LedgerGateway":      ledgerGateway,
+			"OutboxGateway":      outboxGateway,
+		},
+	}
+}
+
+func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork uow.UnitOfWorkInterface) error) error {
+	args := m.Called(fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
+
+func (m *UnitOfWorkMock) Remove(name string) {}
+
+func (m *UnitOfWorkMock) GetRepository(ctx context.Context, name string) (interface{}, error) {
+	return m.Repositories[name], nil
+}
+
+func (m *UnitOfWorkMock) CommitOrRollback() error {
+	return nil
+}
+
+func (m *UnitOfWorkMock) RollBack() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package sweep_idempotency_keys
+
+import (
+	"context"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"log"
+	"time"
+)
+
+const DefaultInterval = time.Hour
+
+type SweepIdempotencyKeysOutput struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// SweepIdempotencyKeysUseCase deletes expired idempotency keys. A request only
+// drops the expired key it was sent with, so keys that are never reused would
+// otherwise stay forever.
+type SweepIdempotencyKeysUseCase struct {
+	IdempotencyGateway gateway.IdempotencyGateway
+	Interval           time.Duration
+
+	now func() time.Time
+}
+
+func NewSweepIdempotencyKeysUseCase(idempotencyGateway gateway.IdempotencyGateway) *SweepIdempotencyKeysUseCase {
+	return &SweepIdempotencyKeysUseCase{
+		IdempotencyGateway: idempotencyGateway,
+		Interval:           DefaultInterval,
+		now:                func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (uc *SweepIdempotencyKeysUseCase) Execute() (*SweepIdempotencyKeysOutput, error) {
+	deleted, err := uc.IdempotencyGateway.DeleteExpired(uc.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SweepIdempotencyKeysOutput{Deleted: deleted}, nil
+}
+
+// Run sweeps right away and then every Interval until ctx is cancelled.
+func (uc *SweepIdempotencyKeysUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := uc.Execute(); err != nil {
+			log.Println("idempotency key sweep:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package sweep_idempotency_keys
+
+import (
+	"context"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestSweepIdempotencyKeysUseCase_Execute_DeleteKeysExpiredByNow(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("DeleteExpired", now).Return(int64(3), nil)
+
+	useCase := NewSweepIdempotencyKeysUseCase(idempotencyGatewayMock)
+	useCase.now = func() time.Time { return now }
+	output, err := useCase.Execute()
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), output.Deleted)
+
+	idempotencyGatewayMock.AssertExpectations(t)
+}
+
+func TestSweepIdempotencyKeysUseCase_Execute_FailDueToGatewayError(t *testing.T) {
+	expectedErrorMessage := "gateway error"
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("DeleteExpired", m.Anything).Return(int64(0), errors.New(expectedErrorMessage))
+
+	useCase := NewSweepIdempotencyKeysUseCase(idempotencyGatewayMock)
+	output, err := useCase.Execute()
+
+	assert.EqualError(t, err, expectedErrorMessage)
+	assert.Nil(t, output)
+}
+
+func TestSweepIdempotencyKeysUseCase_Run_SweepUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	idempotencyGatewayMock := &IdempotencyGatewayMock{}
+	idempotencyGatewayMock.On("DeleteExpired", m.Anything).Return(int64(0), errors.New("gateway error")).Once()
+	idempotencyGatewayMock.On("DeleteExpired", m.Anything).Return(int64(1), nil).Once().
+		Run(func(m.Arguments) { cancel() })
+
+	useCase := NewSweepIdempotencyKeysUseCase(idempotencyGatewayMock)
+	useCase.Interval = time.Millisecond
+	useCase.Run(ctx)
+
+	idempotencyGatewayMock.AssertNumberOfCalls(t, "DeleteExpired", 2)
+}
+
+type IdempotencyGatewayMock struct {
+	m.Mock
+}
+
+func (m *IdempotencyGatewayMock) Create(key *entity.IdempotencyKey) error {
+	panic("implement me")
+}
+
+func (m *IdempotencyGatewayMock) GetByKey(key string) (*entity.IdempotencyKey, error) {
+	panic("implement me")
+}
+
+func (m *IdempotencyGatewayMock) Delete(key string) error {
+	panic("implement me")
+}
+
+func (m *IdempotencyGatewayMock) DeleteExpired(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,72 @@
+// Package transfer holds the steps transfers and reversals share, so both
+// move money between accounts and report it the same way.
+package transfer
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/google/uuid"
+	"sort"
+)
+
+// LoadAccounts reads the given accounts with getAccount in ascending ID
+// order, so two transfers or reversals locking the same accounts in opposite
+// directions can't deadlock.
+func LoadAccounts(
+	getAccount func(ID uuid.UUID) (*entity.Account, error),
+	IDs ...uuid.UUID,
+) (map[uuid.UUID]*entity.Account, error) {
+	ordered := make([]uuid.UUID, len(IDs))
+	copy(ordered, IDs)
+	sort.Slice(ordered, func(i, j int) bool {
+		return bytes.Compare(ordered[i][:], ordered[j][:]) < 0
+	})
+
+	accounts := make(map[uuid.UUID]*entity.Account, len(ordered))
+	for _, ID := range ordered {
+		if _, loaded := accounts[ID]; loaded {
+			continue
+		}
+		account, err := getAccount(ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrAccountNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		accounts[ID] = account
+	}
+	return accounts, nil
+}
+
+// UpdateBalance persists the account balance against the version it was read
+// at and keeps the in-memory version in step with the stored one.
+func UpdateBalance(accountGateway gateway.AccountGateway, account *entity.Account) error {
+	err := accountGateway.UpdateBalance(account.ID, account.Balance, account.Version)
+	if err != nil {
+		return err
+	}
+	account.Version++
+	return nil
+}
+
+// NewBalanceUpdatedEvent reports the balances transaction left its accounts
+// with, keyed by key so it follows the event of the transaction itself.
+func NewBalanceUpdatedEvent(transaction *entity.Transaction, key uuid.UUID) *events.Event {
+	return events.NewPayloadEvent(wallet.AccountBalanceUpdatedV1{
+		TransactionID:       transaction.ID,
+		FromAccountID:       transaction.FromAccount.ID,
+		FromAccountBalance:  transaction.FromAccount.Balance,
+		FromAccountCurrency: string(transaction.FromAccount.Currency),
+		FromAccountVersion:  transaction.FromAccount.Version,
+		ToAccountID:         transaction.ToAccount.ID,
+		ToAccountBalance:    transaction.ToAccount.Balance,
+		ToAccountCurrency:   string(transaction.ToAccount.Currency),
+		ToAccountVersion:    transaction.ToAccount.Version,
+	}).WithKey(key.String())
+}
@@ -0,0 +1,66 @@
+package transfer
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoadAccounts_ReadInAscendingIDOrderOnce(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	if bytes.Compare(first[:], second[:]) > 0 {
+		first, second = second, first
+	}
+
+	var read []uuid.UUID
+	accounts, err := LoadAccounts(func(ID uuid.UUID) (*entity.Account, error) {
+		read = append(read, ID)
+		return &entity.Account{ID: ID}, nil
+	}, second, first, second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uuid.UUID{first, second}, read)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, first, accounts[first].ID)
+}
+
+func TestLoadAccounts_FailDueToAccountNotFound(t *testing.T) {
+	accounts, err := LoadAccounts(func(ID uuid.UUID) (*entity.Account, error) {
+		return nil, sql.ErrNoRows
+	}, uuid.New())
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, accounts)
+}
+
+func TestNewBalanceUpdatedEvent_ReportBothAccounts(t *testing.T) {
+	customer, _ := entity.NewCustomer("customer", "alexandrebrunodias@gmail.com")
+	fromAccount, _ := entity.NewAccount(customer, entity.BRL)
+	toAccount, _ := entity.NewAccount(customer, entity.BRL)
+	_ = fromAccount.Credit(decimal.NewFromInt(100))
+	transaction, err := entity.NewTransaction(fromAccount, toAccount, decimal.NewFromInt(30), nil)
+	require.Nil(t, err)
+	fromAccount.Version, toAccount.Version = 3, 7
+
+	event := NewBalanceUpdatedEvent(transaction, toAccount.ID)
+
+	assert.Equal(t, wallet.AccountBalanceUpdated, event.Name)
+	assert.Equal(t, toAccount.ID.String(), event.Key)
+	assert.Equal(t, wallet.AccountBalanceUpdatedV1{
+		TransactionID:       transaction.ID,
+		FromAccountID:       fromAccount.ID,
+		FromAccountBalance:  fromAccount.Balance,
+		FromAccountCurrency: "BRL",
+		FromAccountVersion:  3,
+		ToAccountID:         toAccount.ID,
+		ToAccountBalance:    toAccount.Balance,
+		ToAccountCurrency:   "BRL",
+		ToAccountVersion:    7,
+	}, event.Content)
+}
@@ -0,0 +1,135 @@
+package update_customer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"time"
+)
+
+const CustomerUpdated = wallet.CustomerUpdated
+
+// UpdateCustomerCommand changes the fields it carries; omitted ones keep
+// their current value.
+type UpdateCustomerCommand struct {
+	ID    uuid.UUID `json:"-"`
+	Name  *string   `json:"name"`
+	Email *string   `json:"email"`
+}
+
+type UpdateCustomerOutput struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateCustomerUseCase struct {
+	UnitOfWork uow.UnitOfWorkInterface
+}
+
+func NewUpdateCustomerUseCase(unitOfWork uow.UnitOfWorkInterface) *UpdateCustomerUseCase {
+	return &UpdateCustomerUseCase{
+		UnitOfWork: unitOfWork,
+	}
+}
+
+func (uc *UpdateCustomerUseCase) Execute(ctx context.Context, command UpdateCustomerCommand) (*UpdateCustomerOutput, error) {
+	output := &UpdateCustomerOutput{}
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		customerGateway := getCustomerGateway(ctx, unitOfWork)
+
+		// Locked until commit, so concurrent updates apply one after the
+		// other and each event carries the fields the previous one left.
+		customer, err := customerGateway.GetByIDForUpdate(command.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrCustomerNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		name, email := customer.Name, customer.Email
+		if command.Name != nil {
+			name = *command.Name
+		}
+		if command.Email != nil {
+			email = entity.NormalizeEmail(*command.Email)
+		}
+
+		// Nothing changes, so nothing is written nor published.
+		if name != customer.Name || email != customer.Email {
+			emailChanged := email != customer.Email
+			if err = customer.Update(name, email); err != nil {
+				return err
+			}
+
+			if emailChanged {
+				owner, err := customerGateway.GetByEmail(email)
+				if err == nil && owner.ID != customer.ID {
+					return entity.ErrEmailAlreadyRegistered
+				}
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+			}
+
+			err = customerGateway.Update(customer)
+			var duplicate *gateway.DuplicateKeyError
+			if errors.As(err, &duplicate) {
+				return entity.ErrEmailAlreadyRegistered
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return entity.ErrCustomerNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			event := events.NewPayloadEvent(wallet.CustomerUpdatedV1{
+				ID:        customer.ID,
+				Name:      customer.Name,
+				Email:     customer.Email,
+				UpdatedAt: customer.UpdatedAt,
+			}).WithKey(customer.ID.String())
+			if err = getOutboxGateway(ctx, unitOfWork).Create(*event); err != nil {
+				return err
+			}
+		}
+
+		output.ID = customer.ID
+		output.Name = customer.Name
+		output.Email = customer.Email
+		output.CreatedAt = customer.CreatedAt
+		output.UpdatedAt = customer.UpdatedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func getCustomerGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.CustomerGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "CustomerGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.CustomerGateway)
+}
+
+func getOutboxGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.OutboxGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "OutboxGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.OutboxGateway)
+}
@@ -0,0 +1,209 @@
+package update_customer
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestUpdateCustomerUseCase_Execute_UpdateSuccessfully(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	createdAt := customer.CreatedAt
+	expectedName := "alexandre"
+	updatedAt := time.Now().UTC().Add(time.Minute)
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByIDForUpdate", customer.ID).Return(customer, nil)
+	customerGatewayMock.On("Update", m.MatchedBy(func(updated *entity.Customer) bool {
+		return updated.ID == customer.ID && updated.Name == expectedName && updated.Email == "alexandrebrunodias@gmail.com"
+	})).Run(func(args m.Arguments) {
+		args.Get(0).(*entity.Customer).UpdatedAt = updatedAt
+	}).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.CustomerUpdatedV1)
+		return event.Name == CustomerUpdated && event.Key == customer.ID.String() && ok &&
+			content.Name == expectedName && content.UpdatedAt.Equal(updatedAt)
+	})).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(customerGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateCustomerUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateCustomerCommand{ID: customer.ID, Name: &expectedName})
+
+	assert.Nil(t, err)
+	assert.Equal(t, customer.ID, output.ID)
+	assert.Equal(t, expectedName, output.Name)
+	assert.Equal(t, "alexandrebrunodias@gmail.com", output.Email)
+	assert.Equal(t, createdAt, output.CreatedAt)
+	assert.Equal(t, updatedAt, output.UpdatedAt)
+
+	customerGatewayMock.AssertExpectations(t)
+	outboxGatewayMock.AssertExpectations(t)
+}
+
+func TestUpdateCustomerUseCase_Execute_SkipUnchangedCustomer(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	sameEmail := customer.Email
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByIDForUpdate", customer.ID).Return(customer, nil)
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(customerGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateCustomerUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateCustomerCommand{ID: customer.ID, Email: &sameEmail})
+
+	assert.Nil(t, err)
+	assert.Equal(t, customer.UpdatedAt, output.UpdatedAt)
+	customerGatewayMock.AssertNotCalled(t, "Update")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestUpdateCustomerUseCase_Execute_FailDueToInvalidEmail(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	invalidEmail := "not-an-email"
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByIDForUpdate", customer.ID).Return(customer, nil)
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(customerGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateCustomerUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateCustomerCommand{ID: customer.ID, Email: &invalidEmail})
+
+	var validationErr *entity.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "email", validationErr.Field)
+	assert.Nil(t, output)
+	assert.Equal(t, "alexandrebrunodias@gmail.com", customer.Email)
+	customerGatewayMock.AssertNotCalled(t, "Update")
+	outboxGatewayMock.AssertNotCalled(t, "Create")
+}
+
+func TestUpdateCustomerUseCase_Execute_FailDueToCustomerNotFound(t *testing.T) {
+	ID := uuid.New()
+	name := "alexandre"
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByIDForUpdate", ID).Return((*entity.Customer)(nil), sql.ErrNoRows)
+
+	unitOfWorkMock := newUnitOfWorkMock(customerGatewayMock, &OutboxGatewayMock{})
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateCustomerUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateCustomerCommand{ID: ID, Name: &name})
+
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	assert.Nil(t, output)
+}
+
+func TestUpdateCustomerUseCase_Execute_FailDueToEmailAlreadyRegistered(t *testing.T) {
+	customer, _ := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	other, _ := entity.NewCustomer("other", "other@gmail.com")
+	takenEmail := "Other@Gmail.com"
+
+	customerGatewayMock := &CustomerGatewayMock{}
+	customerGatewayMock.On("GetByIDForUpdate", customer.ID).Return(customer, nil)
+	customerGatewayMock.On("GetByEmail", "other@gmail.com").Return(other, nil)
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(customerGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateCustomerUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateCustomerCommand{ID: customer.ID, Email: &takenEmail})
+
+	assert.ErrorIs(t, err, entity.ErrEmailAlreadyRegistered)
+	assert.Nil(t, output)
+	customerGatewayMock.AssertNotCalled(t, "Update", m.Anything)
+	outboxGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+}
+
+type CustomerGatewayMock struct {
+	m.Mock
+}
+
+func (m *CustomerGatewayMock) Create(customer *entity.Customer) error {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByID(ID uuid.UUID) (*entity.Customer, error) {
+	panic("implement me")
+}
+
+func (m *CustomerGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Customer, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) GetByEmail(email string) (*entity.Customer, error) {
+	args := m.Called(email)
+	return args.Get(0).(*entity.Customer), args.Error(1)
+}
+
+func (m *CustomerGatewayMock) Update(customer *entity.Customer) error {
+	args := m.Called(customer)
+	return args.Error(0)
+}
+
+type OutboxGatewayMock struct {
+	m.Mock
+}
+
+func (m *OutboxGatewayMock) Create(event events.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+type UnitOfWorkMock struct {
+	m.Mock
+	Repositories map[string]interface{}
+}
+
+func newUnitOfWorkMock(customerGateway *CustomerGatewayMock, outboxGateway *OutboxGatewayMock) *UnitOfWorkMock {
+	return &UnitOfWorkMock{
+		Repositories: map[string]interface{}{
+			"CustomerGateway": customerGateway,
+			"OutboxGateway":   outboxGateway,
+		},
+	}
+}
+
+func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork uow.UnitOfWorkInterface) error) error {
+	args := m.Called(fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
+
+func (m *UnitOfWorkMock) Remove(name string) {}
+
+func (m *UnitOfWorkMock) GetRepository(ctx context.Context, name string) (interface{}, error) {
+	return m.Repositories[name], nil
+}
+
+func (m *UnitOfWorkMock) CommitOrRollback() error {
+	return nil
+}
+
+func (m *UnitOfWorkMock) RollBack() error {
+	return nil
+}
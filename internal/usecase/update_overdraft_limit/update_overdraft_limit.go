@@ -0,0 +1,143 @@
+package update_overdraft_limit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+const AccountLimitChanged = wallet.AccountLimitChanged
+
+// OverdraftLimitChanged is the action audit entries of this use case carry.
+const OverdraftLimitChanged = "overdraft_limit_changed"
+
+// UpdateOverdraftLimitCommand sets the overdraft limit of an account, in its
+// currency. Reason is kept in the audit trail and is required.
+type UpdateOverdraftLimitCommand struct {
+	AccountID      uuid.UUID        `json:"-"`
+	OverdraftLimit *decimal.Decimal `json:"overdraft_limit"`
+	Reason         string           `json:"reason"`
+}
+
+type UpdateOverdraftLimitOutput struct {
+	AccountID              uuid.UUID       `json:"account_id"`
+	Currency               entity.Currency `json:"currency"`
+	Balance                decimal.Decimal `json:"balance"`
+	PreviousOverdraftLimit decimal.Decimal `json:"previous_overdraft_limit"`
+	OverdraftLimit         decimal.Decimal `json:"overdraft_limit"`
+	AvailableBalance       decimal.Decimal `json:"available_balance"`
+}
+
+type UpdateOverdraftLimitUseCase struct {
+	UnitOfWork uow.UnitOfWorkInterface
+}
+
+func NewUpdateOverdraftLimitUseCase(unitOfWork uow.UnitOfWorkInterface) *UpdateOverdraftLimitUseCase {
+	return &UpdateOverdraftLimitUseCase{
+		UnitOfWork: unitOfWork,
+	}
+}
+
+func (uc *UpdateOverdraftLimitUseCase) Execute(
+	ctx context.Context,
+	command UpdateOverdraftLimitCommand,
+) (*UpdateOverdraftLimitOutput, error) {
+	if command.OverdraftLimit == nil {
+		return nil, &entity.ValidationError{Field: "overdraft_limit", Message: "'overdraft_limit' is required"}
+	}
+	reason := strings.TrimSpace(command.Reason)
+	if reason == "" {
+		return nil, &entity.ValidationError{Field: "reason", Message: "'reason' should not be blank"}
+	}
+
+	output := &UpdateOverdraftLimitOutput{}
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		accountGateway := getAccountGateway(ctx, unitOfWork)
+
+		account, err := accountGateway.GetByIDForUpdate(command.AccountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := account.OverdraftLimit
+		// Nothing changes, so nothing is written, audited nor published.
+		if !previous.Equal(*command.OverdraftLimit) {
+			if err = account.ChangeOverdraftLimit(*command.OverdraftLimit); err != nil {
+				return err
+			}
+			if err = accountGateway.UpdateOverdraftLimit(account.ID, account.OverdraftLimit, account.Version); err != nil {
+				return err
+			}
+			account.Version++
+
+			entry := entity.NewAuditEntry(
+				"account", account.ID, OverdraftLimitChanged,
+				previous.String(), account.OverdraftLimit.String(), reason,
+			)
+			if err = getAuditGateway(ctx, unitOfWork).Create(entry); err != nil {
+				return err
+			}
+
+			event := events.NewPayloadEvent(wallet.AccountLimitChangedV1{
+				AccountID:              account.ID,
+				Currency:               string(account.Currency),
+				PreviousOverdraftLimit: previous,
+				OverdraftLimit:         account.OverdraftLimit,
+				Reason:                 reason,
+				Version:                account.Version,
+				ChangedAt:              entry.CreatedAt,
+			}).WithKey(account.ID.String())
+			if err = getOutboxGateway(ctx, unitOfWork).Create(*event); err != nil {
+				return err
+			}
+		}
+
+		output.AccountID = account.ID
+		output.Currency = account.Currency
+		output.Balance = account.Balance
+		output.PreviousOverdraftLimit = previous
+		output.OverdraftLimit = account.OverdraftLimit
+		output.AvailableBalance = account.AvailableBalance()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func getAccountGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AccountGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AccountGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.AccountGateway)
+}
+
+func getAuditGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AuditGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AuditGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.AuditGateway)
+}
+
+func getOutboxGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.OutboxGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "OutboxGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.OutboxGateway)
+}
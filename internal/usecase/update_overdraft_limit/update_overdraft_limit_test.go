@@ -0,0 +1,253 @@
+package update_overdraft_limit
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/wallet"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestUpdateOverdraftLimitUseCase_Execute_UpdateSuccessfully(t *testing.T) {
+	account := newAccount(t)
+	_ = account.Credit(decimal.NewFromInt(100))
+	expectedLimit := decimal.NewFromInt(500)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	accountGatewayMock.On("UpdateOverdraftLimit", account.ID, expectedLimit, int64(0)).Return(nil)
+
+	auditGatewayMock := &AuditGatewayMock{}
+	auditGatewayMock.On("Create", m.MatchedBy(func(entry *entity.AuditEntry) bool {
+		return entry.Entity == "account" && entry.EntityID == account.ID && entry.Action == OverdraftLimitChanged &&
+			entry.OldValue == "0" && entry.NewValue == "500" && entry.Reason == "business plan"
+	})).Return(nil)
+
+	outboxGatewayMock := &OutboxGatewayMock{}
+	outboxGatewayMock.On("Create", m.MatchedBy(func(event events.Event) bool {
+		content, ok := event.Content.(wallet.AccountLimitChangedV1)
+		return event.Name == AccountLimitChanged && event.Key == account.ID.String() && ok &&
+			content.PreviousOverdraftLimit.IsZero() && content.OverdraftLimit.Equal(expectedLimit) &&
+			content.Currency == "BRL" && content.Version == 1
+	})).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateOverdraftLimitUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateOverdraftLimitCommand{
+		AccountID:      account.ID,
+		OverdraftLimit: &expectedLimit,
+		Reason:         " business plan ",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, account.ID, output.AccountID)
+	assert.True(t, output.PreviousOverdraftLimit.IsZero())
+	assert.Equal(t, "500", output.OverdraftLimit.String())
+	assert.Equal(t, "600", output.AvailableBalance.String())
+
+	accountGatewayMock.AssertExpectations(t)
+	auditGatewayMock.AssertExpectations(t)
+	outboxGatewayMock.AssertExpectations(t)
+}
+
+func TestUpdateOverdraftLimitUseCase_Execute_SkipUnchangedLimit(t *testing.T) {
+	account := newAccount(t)
+	sameLimit := decimal.RequireFromString("0.00")
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	auditGatewayMock := &AuditGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateOverdraftLimitUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateOverdraftLimitCommand{
+		AccountID:      account.ID,
+		OverdraftLimit: &sameLimit,
+		Reason:         "no change",
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, output.OverdraftLimit.IsZero())
+	accountGatewayMock.AssertNotCalled(t, "UpdateOverdraftLimit", m.Anything, m.Anything, m.Anything)
+	auditGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+	outboxGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+}
+
+func TestUpdateOverdraftLimitUseCase_Execute_FailDueToBlankReason(t *testing.T) {
+	limit := decimal.NewFromInt(500)
+	unitOfWorkMock := newUnitOfWorkMock(&AccountGatewayMock{}, &AuditGatewayMock{}, &OutboxGatewayMock{})
+
+	useCase := NewUpdateOverdraftLimitUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateOverdraftLimitCommand{
+		AccountID:      uuid.New(),
+		OverdraftLimit: &limit,
+		Reason:         "  ",
+	})
+
+	var validationErr *entity.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "reason", validationErr.Field)
+	assert.Nil(t, output)
+	unitOfWorkMock.AssertNotCalled(t, "Do", m.Anything)
+}
+
+func TestUpdateOverdraftLimitUseCase_Execute_FailDueToLimitBelowOverdrawnBalance(t *testing.T) {
+	account := newAccount(t)
+	_ = account.ChangeOverdraftLimit(decimal.NewFromInt(500))
+	_ = account.Debit(decimal.NewFromInt(300))
+	limit := decimal.NewFromInt(100)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	auditGatewayMock := &AuditGatewayMock{}
+	outboxGatewayMock := &OutboxGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock, outboxGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateOverdraftLimitUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateOverdraftLimitCommand{
+		AccountID:      account.ID,
+		OverdraftLimit: &limit,
+		Reason:         "downgrade",
+	})
+
+	var unprocessable *entity.UnprocessableError
+	assert.ErrorAs(t, err, &unprocessable)
+	assert.Equal(t, "overdraft_limit_below_balance", unprocessable.Code)
+	assert.Nil(t, output)
+	accountGatewayMock.AssertNotCalled(t, "UpdateOverdraftLimit", m.Anything, m.Anything, m.Anything)
+	auditGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+	outboxGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+}
+
+func TestUpdateOverdraftLimitUseCase_Execute_FailDueToAccountNotFound(t *testing.T) {
+	ID := uuid.New()
+	limit := decimal.NewFromInt(500)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", ID).Return((*entity.Account)(nil), sql.ErrNoRows)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, &AuditGatewayMock{}, &OutboxGatewayMock{})
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateOverdraftLimitUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateOverdraftLimitCommand{
+		AccountID:      ID,
+		OverdraftLimit: &limit,
+		Reason:         "business plan",
+	})
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, output)
+}
+
+func newAccount(t *testing.T) *entity.Account {
+	customer, err := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	assert.Nil(t, err)
+	account, err := entity.NewAccount(customer, entity.BRL)
+	assert.Nil(t, err)
+	return account
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	args := m.Called(ID, limit, version)
+	return args.Error(0)
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	panic("implement me")
+}
+
+type AuditGatewayMock struct {
+	m.Mock
+}
+
+func (m *AuditGatewayMock) Create(entry *entity.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+type OutboxGatewayMock struct {
+	m.Mock
+}
+
+func (m *OutboxGatewayMock) Create(event events.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+type UnitOfWorkMock struct {
+	m.Mock
+	Repositories map[string]interface{}
+}
+
+func newUnitOfWorkMock(
+	accountGateway *AccountGatewayMock,
+	auditGateway *AuditGatewayMock,
+	outboxGateway *OutboxGatewayMock,
+) *UnitOfWorkMock {
+	return &UnitOfWorkMock{
+		Repositories: map[string]interface{}{
+			"AccountGateway": accountGateway,
+			"AuditGateway":   auditGateway,
+			"OutboxGateway":  outboxGateway,
+		},
+	}
+}
+
+func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork uow.UnitOfWorkInterface) error) error {
+	args := m.Called(fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
+
+func (m *UnitOfWorkMock) Remove(name string) {}
+
+func (m *UnitOfWorkMock) GetRepository(ctx context.Context, name string) (interface{}, error) {
+	return m.Repositories[name], nil
+}
+
+func (m *UnitOfWorkMock) CommitOrRollback() error {
+	return nil
+}
+
+func (m *UnitOfWorkMock) RollBack() error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package update_transfer_limits
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// TransferLimitsChanged is the action audit entries of this use case carry.
+const TransferLimitsChanged = "transfer_limits_changed"
+
+// UpdateTransferLimitsCommand replaces every transfer limit of an account, in
+// its currency: omitted or null limits are lifted. Reason is kept in the
+// audit trail and is required.
+type UpdateTransferLimitsCommand struct {
+	AccountID         uuid.UUID        `json:"-"`
+	MaxTransferAmount *decimal.Decimal `json:"max_transfer_amount"`
+	MaxDailyAmount    *decimal.Decimal `json:"max_daily_amount"`
+	MaxDailyCount     *int64           `json:"max_daily_count"`
+	Reason            string           `json:"reason"`
+}
+
+type UpdateTransferLimitsOutput struct {
+	AccountID         uuid.UUID        `json:"account_id"`
+	Currency          entity.Currency  `json:"currency"`
+	MaxTransferAmount *decimal.Decimal `json:"max_transfer_amount"`
+	MaxDailyAmount    *decimal.Decimal `json:"max_daily_amount"`
+	MaxDailyCount     *int64           `json:"max_daily_count"`
+}
+
+type UpdateTransferLimitsUseCase struct {
+	UnitOfWork uow.UnitOfWorkInterface
+}
+
+func NewUpdateTransferLimitsUseCase(unitOfWork uow.UnitOfWorkInterface) *UpdateTransferLimitsUseCase {
+	return &UpdateTransferLimitsUseCase{
+		UnitOfWork: unitOfWork,
+	}
+}
+
+func (uc *UpdateTransferLimitsUseCase) Execute(
+	ctx context.Context,
+	command UpdateTransferLimitsCommand,
+) (*UpdateTransferLimitsOutput, error) {
+	reason := strings.TrimSpace(command.Reason)
+	if reason == "" {
+		return nil, &entity.ValidationError{Field: "reason", Message: "'reason' should not be blank"}
+	}
+	limits := entity.TransferLimits{
+		MaxTransferAmount: command.MaxTransferAmount,
+		MaxDailyAmount:    command.MaxDailyAmount,
+		MaxDailyCount:     command.MaxDailyCount,
+	}
+
+	output := &UpdateTransferLimitsOutput{}
+	err := uc.UnitOfWork.Do(ctx, func(unitOfWork uow.UnitOfWorkInterface) error {
+		accountGateway := getAccountGateway(ctx, unitOfWork)
+
+		account, err := accountGateway.GetByIDForUpdate(command.AccountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := account.TransferLimits
+		// Nothing changes, so nothing is written nor audited.
+		if !previous.Equal(limits) {
+			if err = account.ChangeTransferLimits(limits); err != nil {
+				return err
+			}
+			if err = accountGateway.UpdateTransferLimits(account.ID, account.TransferLimits, account.Version); err != nil {
+				return err
+			}
+			account.Version++
+
+			entry := entity.NewAuditEntry(
+				"account", account.ID, TransferLimitsChanged,
+				previous.String(), account.TransferLimits.String(), reason,
+			)
+			if err = getAuditGateway(ctx, unitOfWork).Create(entry); err != nil {
+				return err
+			}
+		}
+
+		output.AccountID = account.ID
+		output.Currency = account.Currency
+		output.MaxTransferAmount = account.TransferLimits.MaxTransferAmount
+		output.MaxDailyAmount = account.TransferLimits.MaxDailyAmount
+		output.MaxDailyCount = account.TransferLimits.MaxDailyCount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func getAccountGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AccountGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AccountGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.AccountGateway)
+}
+
+func getAuditGateway(ctx context.Context, unitOfWork uow.UnitOfWorkInterface) gateway.AuditGateway {
+	repository, err := unitOfWork.GetRepository(ctx, "AuditGateway")
+	if err != nil {
+		panic(err)
+	}
+	return repository.(gateway.AuditGateway)
+}
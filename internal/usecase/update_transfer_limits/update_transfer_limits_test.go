@@ -0,0 +1,220 @@
+package update_transfer_limits
+
+import (
+	"context"
+	"database/sql"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/pkg/uow"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestUpdateTransferLimitsUseCase_Execute_UpdateSuccessfully(t *testing.T) {
+	account := newAccount(t)
+	maxTransfer := decimal.NewFromInt(1000)
+	maxCount := int64(10)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	accountGatewayMock.On("UpdateTransferLimits", account.ID, m.MatchedBy(func(limits entity.TransferLimits) bool {
+		return limits.MaxTransferAmount.Equal(maxTransfer) && limits.MaxDailyAmount == nil && *limits.MaxDailyCount == maxCount
+	}), int64(0)).Return(nil)
+
+	auditGatewayMock := &AuditGatewayMock{}
+	auditGatewayMock.On("Create", m.MatchedBy(func(entry *entity.AuditEntry) bool {
+		return entry.Entity == "account" && entry.EntityID == account.ID && entry.Action == TransferLimitsChanged &&
+			entry.OldValue == "max_transfer_amount=none max_daily_amount=none max_daily_count=none" &&
+			entry.NewValue == "max_transfer_amount=1000 max_daily_amount=none max_daily_count=10" &&
+			entry.Reason == "fraud review"
+	})).Return(nil)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateTransferLimitsUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateTransferLimitsCommand{
+		AccountID:         account.ID,
+		MaxTransferAmount: &maxTransfer,
+		MaxDailyCount:     &maxCount,
+		Reason:            "fraud review",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, account.ID, output.AccountID)
+	assert.Equal(t, "1000", output.MaxTransferAmount.String())
+	assert.Nil(t, output.MaxDailyAmount)
+	assert.Equal(t, maxCount, *output.MaxDailyCount)
+	assert.Equal(t, int64(1), account.Version)
+
+	accountGatewayMock.AssertExpectations(t)
+	auditGatewayMock.AssertExpectations(t)
+}
+
+func TestUpdateTransferLimitsUseCase_Execute_SkipUnchangedLimits(t *testing.T) {
+	account := newAccount(t)
+	maxDaily := decimal.NewFromInt(5000)
+	_ = account.ChangeTransferLimits(entity.TransferLimits{MaxDailyAmount: &maxDaily})
+	sameMaxDaily := decimal.RequireFromString("5000.00")
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	auditGatewayMock := &AuditGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateTransferLimitsUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateTransferLimitsCommand{
+		AccountID:      account.ID,
+		MaxDailyAmount: &sameMaxDaily,
+		Reason:         "no change",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "5000", output.MaxDailyAmount.String())
+	accountGatewayMock.AssertNotCalled(t, "UpdateTransferLimits", m.Anything, m.Anything, m.Anything)
+	auditGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+}
+
+func TestUpdateTransferLimitsUseCase_Execute_FailDueToInvalidLimit(t *testing.T) {
+	account := newAccount(t)
+	negativeCount := int64(-1)
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", account.ID).Return(account, nil)
+	auditGatewayMock := &AuditGatewayMock{}
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, auditGatewayMock)
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateTransferLimitsUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateTransferLimitsCommand{
+		AccountID:     account.ID,
+		MaxDailyCount: &negativeCount,
+		Reason:        "fraud review",
+	})
+
+	var validationErr *entity.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, entity.MaxDailyCount, validationErr.Field)
+	assert.Nil(t, output)
+	accountGatewayMock.AssertNotCalled(t, "UpdateTransferLimits", m.Anything, m.Anything, m.Anything)
+	auditGatewayMock.AssertNotCalled(t, "Create", m.Anything)
+}
+
+func TestUpdateTransferLimitsUseCase_Execute_FailDueToBlankReason(t *testing.T) {
+	unitOfWorkMock := newUnitOfWorkMock(&AccountGatewayMock{}, &AuditGatewayMock{})
+
+	useCase := NewUpdateTransferLimitsUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateTransferLimitsCommand{AccountID: uuid.New()})
+
+	var validationErr *entity.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "reason", validationErr.Field)
+	assert.Nil(t, output)
+	unitOfWorkMock.AssertNotCalled(t, "Do", m.Anything)
+}
+
+func TestUpdateTransferLimitsUseCase_Execute_FailDueToAccountNotFound(t *testing.T) {
+	ID := uuid.New()
+
+	accountGatewayMock := &AccountGatewayMock{}
+	accountGatewayMock.On("GetByIDForUpdate", ID).Return((*entity.Account)(nil), sql.ErrNoRows)
+
+	unitOfWorkMock := newUnitOfWorkMock(accountGatewayMock, &AuditGatewayMock{})
+	unitOfWorkMock.On("Do", m.Anything).Return(nil)
+
+	useCase := NewUpdateTransferLimitsUseCase(unitOfWorkMock)
+	output, err := useCase.Execute(context.Background(), UpdateTransferLimitsCommand{AccountID: ID, Reason: "fraud review"})
+
+	assert.ErrorIs(t, err, entity.ErrAccountNotFound)
+	assert.Nil(t, output)
+}
+
+func newAccount(t *testing.T) *entity.Account {
+	customer, err := entity.NewCustomer("alex", "alexandrebrunodias@gmail.com")
+	assert.Nil(t, err)
+	account, err := entity.NewAccount(customer, entity.BRL)
+	assert.Nil(t, err)
+	return account
+}
+
+type AccountGatewayMock struct {
+	m.Mock
+}
+
+func (m *AccountGatewayMock) Create(account *entity.Account) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByID(ID uuid.UUID) (*entity.Account, error) {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) GetByIDForUpdate(ID uuid.UUID) (*entity.Account, error) {
+	args := m.Called(ID)
+	return args.Get(0).(*entity.Account), args.Error(1)
+}
+
+func (m *AccountGatewayMock) UpdateBalance(ID uuid.UUID, amount decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateOverdraftLimit(ID uuid.UUID, limit decimal.Decimal, version int64) error {
+	panic("implement me")
+}
+
+func (m *AccountGatewayMock) UpdateTransferLimits(ID uuid.UUID, limits entity.TransferLimits, version int64) error {
+	args := m.Called(ID, limits, version)
+	return args.Error(0)
+}
+
+type AuditGatewayMock struct {
+	m.Mock
+}
+
+func (m *AuditGatewayMock) Create(entry *entity.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+type UnitOfWorkMock struct {
+	m.Mock
+	Repositories map[string]interface{}
+}
+
+func newUnitOfWorkMock(accountGateway *AccountGatewayMock, auditGateway *AuditGatewayMock) *UnitOfWorkMock {
+	return &UnitOfWorkMock{
+		Repositories: map[string]interface{}{
+			"AccountGateway": accountGateway,
+			"AuditGateway":   auditGateway,
+		},
+	}
+}
+
+func (m *UnitOfWorkMock) Do(_ context.Context, fn func(unitOfWork uow.UnitOfWorkInterface) error) error {
+	args := m.Called(fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *UnitOfWorkMock) Add(name string, repository uow.Repository) {}
+
+func (m *UnitOfWorkMock) Remove(name string) {}
+
+func (m *UnitOfWorkMock) GetRepository(ctx context.Context, name string) (interface{}, error) {
+	return m.Repositories[name], nil
+}
+
+func (m *UnitOfWorkMock) CommitOrRollback() error {
+	return nil
+}
+
+func (m *UnitOfWorkMock) RollBack() error {
+	return nil
+}
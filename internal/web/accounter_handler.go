@@ -2,45 +2,194 @@ package web
 
 import (
 	"encoding/json"
-	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_account"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_account"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_account_statement"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_overdraft_limit"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_transfer_limits"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type AccountHandler struct {
-	CreateAccountUseCase create_account.CreateAccountUseCase
+	CreateAccountUseCase        create_account.CreateAccountUseCase
+	GetAccountUseCase           get_account.GetAccountUseCase
+	GetAccountStatementUseCase  get_account_statement.GetAccountStatementUseCase
+	UpdateOverdraftLimitUseCase update_overdraft_limit.UpdateOverdraftLimitUseCase
+	UpdateTransferLimitsUseCase update_transfer_limits.UpdateTransferLimitsUseCase
 }
 
-func NewAccountHandler(createAccountUseCase create_account.CreateAccountUseCase) *AccountHandler {
+func NewAccountHandler(
+	createAccountUseCase create_account.CreateAccountUseCase,
+	getAccountUseCase get_account.GetAccountUseCase,
+	getAccountStatementUseCase get_account_statement.GetAccountStatementUseCase,
+	updateOverdraftLimitUseCase update_overdraft_limit.UpdateOverdraftLimitUseCase,
+	updateTransferLimitsUseCase update_transfer_limits.UpdateTransferLimitsUseCase,
+) *AccountHandler {
 	if &createAccountUseCase == nil {
 		panic("'CreateAccountUseCase' must not be nil")
 	}
-	return &AccountHandler{CreateAccountUseCase: createAccountUseCase}
+	if &getAccountUseCase == nil {
+		panic("'GetAccountUseCase' must not be nil")
+	}
+	if &getAccountStatementUseCase == nil {
+		panic("'GetAccountStatementUseCase' must not be nil")
+	}
+	if &updateOverdraftLimitUseCase == nil {
+		panic("'UpdateOverdraftLimitUseCase' must not be nil")
+	}
+	if &updateTransferLimitsUseCase == nil {
+		panic("'UpdateTransferLimitsUseCase' must not be nil")
+	}
+	return &AccountHandler{
+		CreateAccountUseCase:        createAccountUseCase,
+		GetAccountUseCase:           getAccountUseCase,
+		GetAccountStatementUseCase:  getAccountStatementUseCase,
+		UpdateOverdraftLimitUseCase: updateOverdraftLimitUseCase,
+		UpdateTransferLimitsUseCase: updateTransferLimitsUseCase,
+	}
 }
 
 func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	var command create_account.CreateAccountCommand
 	err := json.NewDecoder(r.Body).Decode(&command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Println(err)
+		writeError(w, invalidRequest("", err))
 		return
 	}
 
 	output, err := h.CreateAccountUseCase.Execute(command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Println(err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	ID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
+		return
+	}
+
+	output, err := h.GetAccountUseCase.Execute(get_account.GetAccountQuery{ID: ID})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (h *AccountHandler) GetAccountStatement(w http.ResponseWriter, r *http.Request) {
+	query, err := newAccountStatementQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	output, err := h.GetAccountStatementUseCase.Execute(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (h *AccountHandler) UpdateOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	var command update_overdraft_limit.UpdateOverdraftLimitCommand
+	err := json.NewDecoder(r.Body).Decode(&command)
+	if err != nil {
+		writeError(w, invalidRequest("", err))
+		return
+	}
+
+	command.AccountID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
+		return
+	}
+
+	output, err := h.UpdateOverdraftLimitUseCase.Execute(r.Context(), command)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+// UpdateTransferLimits is meant for back-office operators: it replaces every
+// transfer limit of the account.
+func (h *AccountHandler) UpdateTransferLimits(w http.ResponseWriter, r *http.Request) {
+	var command update_transfer_limits.UpdateTransferLimitsCommand
+	err := json.NewDecoder(r.Body).Decode(&command)
+	if err != nil {
+		writeError(w, invalidRequest("", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	command.AccountID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
+		return
+	}
 
-	err = json.NewEncoder(w).Encode(output)
+	output, err := h.UpdateTransferLimitsUseCase.Execute(r.Context(), command)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Println(err)
+		writeError(w, err)
 		return
 	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+func newAccountStatementQuery(r *http.Request) (get_account_statement.GetAccountStatementQuery, error) {
+	var query get_account_statement.GetAccountStatementQuery
+	var err error
+
+	query.AccountID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return query, invalidRequest("id", err)
+	}
+
+	params := r.URL.Query()
+	query.Direction = entity.TransferDirection(params.Get("direction"))
+	query.Cursor = params.Get("cursor")
+
+	if limit := params.Get("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return query, invalidRequest("limit", err)
+		}
+	}
+
+	query.From, err = parseTimeParam(params.Get("from"))
+	if err != nil {
+		return query, invalidRequest("from", err)
+	}
+	query.To, err = parseTimeParam(params.Get("to"))
+	if err != nil {
+		return query, invalidRequest("to", err)
+	}
+
+	return query, nil
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
 }
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"crypto/subtle"
+	"github.com/go-chi/chi"
+	"net/http"
+	"strings"
+)
+
+// RequireAdminToken lets through requests bearing token, as in
+// "Authorization: Bearer <token>", and answers 401 to any other.
+func RequireAdminToken(token string) func(http.Handler) http.Handler {
+	if token == "" {
+		panic("'token' must not be blank")
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, Problem{Code: "unauthorized", Message: "a valid admin token is required"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AdminRoutes mounts the back-office endpoints behind RequireAdminToken: the
+// ones granting credit or changing transfer limits, which no customer may
+// reach, and the ledger reconciliation.
+func AdminRoutes(token string, accounts *AccountHandler, ledger *LedgerHandler) func(chi.Router) {
+	return func(admin chi.Router) {
+		admin.Use(RequireAdminToken(token))
+		admin.Put("/accounts/{id}/overdraft-limit", accounts.UpdateOverdraftLimit)
+		admin.Put("/accounts/{id}/transfer-limits", accounts.UpdateTransferLimits)
+		admin.Get("/ledger/reconciliation", ledger.ReconcileLedger)
+	}
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireAdminToken_RejectMissingOrWrongToken(t *testing.T) {
+	handler := RequireAdminToken("admin-token-1234567890")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, test := range []struct {
+		authorization  string
+		expectedStatus int
+	}{
+		{"Bearer admin-token-1234567890", http.StatusNoContent},
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong-token", http.StatusUnauthorized},
+		{"admin-token-1234567890", http.StatusUnauthorized},
+	} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPut, "/admin/accounts", nil)
+		if test.authorization != "" {
+			request.Header.Set("Authorization", test.authorization)
+		}
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.authorization)
+	}
+}
+
+func TestAdminRoutes_GuardOverdraftLimitChanges(t *testing.T) {
+	token := "admin-token-1234567890"
+	router := chi.NewRouter()
+	router.Route("/admin", AdminRoutes(token, &AccountHandler{}, &LedgerHandler{}))
+	path := "/accounts/" + uuid.New().String() + "/overdraft-limit"
+
+	for _, test := range []struct {
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{"/admin" + path, "", http.StatusUnauthorized},
+		{"/admin" + path, "Bearer wrong-token", http.StatusUnauthorized},
+		// Reaches the handler, which refuses the malformed body.
+		{"/admin" + path, "Bearer " + token, http.StatusBadRequest},
+		{path, "Bearer " + token, http.StatusNotFound},
+	} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPut, test.path, strings.NewReader("{"))
+		if test.authorization != "" {
+			request.Header.Set("Authorization", test.authorization)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.path+" "+test.authorization)
+	}
+}
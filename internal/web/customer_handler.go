@@ -2,45 +2,113 @@ package web
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_customer"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_customer"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/update_customer"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
 	"net/http"
+	"strings"
 )
 
 type CustomerHandler struct {
 	CreateCustomerUseCase create_customer.CreateCustomerUseCase
+	GetCustomerUseCase    get_customer.GetCustomerUseCase
+	UpdateCustomerUseCase update_customer.UpdateCustomerUseCase
 }
 
-func NewCustomerHandler(createCustomerUseCase create_customer.CreateCustomerUseCase) *CustomerHandler {
+func NewCustomerHandler(
+	createCustomerUseCase create_customer.CreateCustomerUseCase,
+	getCustomerUseCase get_customer.GetCustomerUseCase,
+	updateCustomerUseCase update_customer.UpdateCustomerUseCase,
+) *CustomerHandler {
 	if &createCustomerUseCase == nil {
 		panic("'CreateCustomerUseCase' must not be nil")
 	}
-	return &CustomerHandler{CreateCustomerUseCase: createCustomerUseCase}
+	if &getCustomerUseCase == nil {
+		panic("'GetCustomerUseCase' must not be nil")
+	}
+	if &updateCustomerUseCase == nil {
+		panic("'UpdateCustomerUseCase' must not be nil")
+	}
+	return &CustomerHandler{
+		CreateCustomerUseCase: createCustomerUseCase,
+		GetCustomerUseCase:    getCustomerUseCase,
+		UpdateCustomerUseCase: updateCustomerUseCase,
+	}
 }
 
 func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
 	var command create_customer.CreateCustomerCommand
 	err := json.NewDecoder(r.Body).Decode(&command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Println(err)
+		writeError(w, invalidRequest("", err))
 		return
 	}
 
 	output, err := h.CreateCustomerUseCase.Execute(command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Println(err)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	ID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	output, err := h.GetCustomerUseCase.Execute(get_customer.GetCustomerQuery{ID: ID})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+// FindCustomer serves GET /customers?email=, e-mail being the only lookup
+// supported.
+func (h *CustomerHandler) FindCustomer(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.URL.Query().Get("email"))
+	if email == "" {
+		writeError(w, invalidRequest("email", errors.New("'email' query parameter is required")))
+		return
+	}
 
-	err = json.NewEncoder(w).Encode(output)
+	output, err := h.GetCustomerUseCase.Execute(get_customer.GetCustomerQuery{Email: email})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Println(err)
+		writeError(w, err)
 		return
 	}
+
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	var command update_customer.UpdateCustomerCommand
+	err := json.NewDecoder(r.Body).Decode(&command)
+	if err != nil {
+		writeError(w, invalidRequest("", err))
+		return
+	}
+
+	command.ID, err = uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
+		return
+	}
+
+	output, err := h.UpdateCustomerUseCase.Execute(r.Context(), command)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
 }
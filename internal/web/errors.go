@@ -0,0 +1,98 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"log"
+	"net/http"
+)
+
+// Problem is the body of every error response.
+type Problem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// requestError reports a request that could not be decoded, before it reaches
+// any use case.
+type requestError struct {
+	field string
+	err   error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+func invalidRequest(field string, err error) error {
+	return &requestError{field: field, err: err}
+}
+
+// writeError maps err to a status and a Problem body. Errors that aren't part
+// of the error model are logged and reported as a bare 500, so database
+// errors never reach clients.
+func writeError(w http.ResponseWriter, err error) {
+	status, problem := toProblem(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("internal error: %v", err)
+	}
+	writeJSON(w, status, problem)
+}
+
+func toProblem(err error) (int, Problem) {
+	var request *requestError
+	var validation *entity.ValidationError
+	var notFound *entity.NotFoundError
+	var conflict *entity.ConflictError
+	var versionConflict *gateway.VersionConflictError
+	var duplicate *gateway.DuplicateKeyError
+	var insufficientFunds *entity.InsufficientFundsError
+	var limitExceeded *entity.LimitExceededError
+	var unprocessable *entity.UnprocessableError
+
+	switch {
+	case errors.As(err, &request):
+		return http.StatusBadRequest, Problem{Code: "invalid_request", Message: request.Error(), Field: request.field}
+	case errors.As(err, &validation):
+		return http.StatusBadRequest, Problem{Code: "validation_failed", Message: validation.Message, Field: validation.Field}
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, Problem{Code: "not_found", Message: notFound.Error()}
+	case errors.As(err, &conflict):
+		code := conflict.Code
+		if code == "" {
+			code = "conflict"
+		}
+		return http.StatusConflict, Problem{Code: code, Message: conflict.Message}
+	case errors.As(err, &versionConflict), errors.As(err, &duplicate):
+		return http.StatusConflict, Problem{Code: "conflict", Message: "the resource was modified concurrently, try again"}
+	case errors.As(err, &insufficientFunds):
+		return http.StatusUnprocessableEntity, Problem{Code: "insufficient_funds", Message: insufficientFunds.Error()}
+	case errors.As(err, &limitExceeded):
+		return http.StatusUnprocessableEntity, Problem{
+			Code:    "transfer_limit_exceeded",
+			Message: limitExceeded.Error(),
+			Field:   limitExceeded.Limit,
+		}
+	case errors.As(err, &unprocessable):
+		return http.StatusUnprocessableEntity, Problem{Code: unprocessable.Code, Message: unprocessable.Message}
+	default:
+		return http.StatusInternalServerError, Problem{Code: "internal_error", Message: "internal server error"}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
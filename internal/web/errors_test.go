@@ -0,0 +1,65 @@
+package web
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alexandrebrunodias/wallet-core/internal/entity"
+	"github.com/alexandrebrunodias/wallet-core/internal/gateway"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestToProblem_MapErrorModel(t *testing.T) {
+	for _, test := range []struct {
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{invalidRequest("id", errors.New("invalid UUID length: 3")), http.StatusBadRequest, "invalid_request", "id"},
+		{&entity.ValidationError{Field: "email", Message: "'email' is invalid"}, http.StatusBadRequest, "validation_failed", "email"},
+		{entity.ErrAccountNotFound, http.StatusNotFound, "not_found", ""},
+		{&entity.ConflictError{Message: "a reversal cannot be reversed"}, http.StatusConflict, "conflict", ""},
+		{entity.ErrEmailAlreadyRegistered, http.StatusConflict, "email_already_registered", ""},
+		{&gateway.VersionConflictError{Entity: "account", ID: uuid.New()}, http.StatusConflict, "conflict", ""},
+		{&entity.InsufficientFundsError{}, http.StatusUnprocessableEntity, "insufficient_funds", ""},
+		{&entity.LimitExceededError{Limit: entity.MaxDailyAmount}, http.StatusUnprocessableEntity, "transfer_limit_exceeded", "max_daily_amount"},
+		{&entity.UnprocessableError{Code: "idempotency_key_reused"}, http.StatusUnprocessableEntity, "idempotency_key_reused", ""},
+		{fmt.Errorf("wrapped: %w", entity.ErrCustomerNotFound), http.StatusNotFound, "not_found", ""},
+		{sql.ErrConnDone, http.StatusInternalServerError, "internal_error", ""},
+	} {
+		status, problem := toProblem(test.err)
+
+		assert.Equal(t, test.expectedStatus, status, test.err.Error())
+		assert.Equal(t, test.expectedCode, problem.Code, test.err.Error())
+		assert.Equal(t, test.expectedField, problem.Field, test.err.Error())
+	}
+}
+
+func TestWriteError_HideUnexpectedErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	writeError(recorder, errors.New(`pq: relation "accounts" does not exist`))
+
+	var problem Problem
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&problem))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "internal server error", problem.Message)
+}
+
+func TestWriteError_EscapeMessage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	writeError(recorder, &entity.ValidationError{Field: "name", Message: `"name" is "blank"`})
+
+	var problem Problem
+	assert.Nil(t, json.NewDecoder(recorder.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `"name" is "blank"`, problem.Message)
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/reconcile_ledger"
+	"net/http"
+)
+
+type LedgerHandler struct {
+	ReconcileLedgerUseCase reconcile_ledger.ReconcileLedgerUseCase
+}
+
+func NewLedgerHandler(reconcileLedgerUseCase reconcile_ledger.ReconcileLedgerUseCase) *LedgerHandler {
+	if &reconcileLedgerUseCase == nil {
+		panic("'ReconcileLedgerUseCase' must not be nil")
+	}
+	return &LedgerHandler{
+		ReconcileLedgerUseCase: reconcileLedgerUseCase,
+	}
+}
+
+// ReconcileLedger reports the accounts whose balance disagrees with their
+// ledger entries. It reads every account, so it is only served to admins.
+func (h *LedgerHandler) ReconcileLedger(w http.ResponseWriter, r *http.Request) {
+	output, err := h.ReconcileLedgerUseCase.Execute()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
+}
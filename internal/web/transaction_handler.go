@@ -2,49 +2,102 @@ package web
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"github.com/alexandrebrunodias/wallet-core/internal/usecase/create_transaction"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/get_transaction"
+	"github.com/alexandrebrunodias/wallet-core/internal/usecase/reverse_transaction"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"io"
 	"net/http"
 )
 
 type TransactionHandler struct {
-	CreateTransactionUseCase create_transaction.CreateTransactionUseCase
+	CreateTransactionUseCase  create_transaction.CreateTransactionUseCase
+	ReverseTransactionUseCase reverse_transaction.ReverseTransactionUseCase
+	GetTransactionUseCase     get_transaction.GetTransactionUseCase
 }
 
-func NewTransactionHandler(createTransactionUseCase create_transaction.CreateTransactionUseCase) *TransactionHandler {
+func NewTransactionHandler(
+	createTransactionUseCase create_transaction.CreateTransactionUseCase,
+	reverseTransactionUseCase reverse_transaction.ReverseTransactionUseCase,
+	getTransactionUseCase get_transaction.GetTransactionUseCase,
+) *TransactionHandler {
 	if &createTransactionUseCase == nil {
 		panic("'CreateTransactionUseCase' must not be nil")
 	}
-	return &TransactionHandler{CreateTransactionUseCase: createTransactionUseCase}
+	if &reverseTransactionUseCase == nil {
+		panic("'ReverseTransactionUseCase' must not be nil")
+	}
+	if &getTransactionUseCase == nil {
+		panic("'GetTransactionUseCase' must not be nil")
+	}
+	return &TransactionHandler{
+		CreateTransactionUseCase:  createTransactionUseCase,
+		ReverseTransactionUseCase: reverseTransactionUseCase,
+		GetTransactionUseCase:     getTransactionUseCase,
+	}
 }
 
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 	var command create_transaction.CreateTransactionCommand
 	err := json.NewDecoder(r.Body).Decode(&command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		// TODO IMPROVE ERROR HANDLING
-		w.Write([]byte("{\"error\":\"" + err.Error() + "\"}"))
-		fmt.Println(err)
+		writeError(w, invalidRequest("", err))
 		return
 	}
 
+	command.IdempotencyKey = r.Header.Get("Idempotency-Key")
+
 	requestContext := r.Context()
 	output, err := h.CreateTransactionUseCase.Execute(requestContext, command)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte("{\"error\":\"" + err.Error() + "\"}"))
-		fmt.Println(err)
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	if output.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
 
-	err = json.NewEncoder(w).Encode(output)
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	var command reverse_transaction.ReverseTransactionCommand
+	// The body is optional: without an amount the whole remainder is reversed.
+	err := json.NewDecoder(r.Body).Decode(&command)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, invalidRequest("", err))
+		return
+	}
+
+	command.TransactionID, err = uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Println(err)
+		writeError(w, invalidRequest("id", err))
 		return
 	}
+
+	output, err := h.ReverseTransactionUseCase.Execute(r.Context(), command)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	ID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, invalidRequest("id", err))
+		return
+	}
+
+	output, err := h.GetTransactionUseCase.Execute(get_transaction.GetTransactionQuery{ID: ID})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output)
 }
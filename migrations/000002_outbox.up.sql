@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id UUID PRIMARY KEY,
+  name text NOT NULL,
+  payload JSONB NOT NULL,
+  occurred_at TIMESTAMP NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP NOT NULL,
+  delivered_at TIMESTAMP,
+  last_error text,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, created_at) WHERE delivered_at IS NULL;
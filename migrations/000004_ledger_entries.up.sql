@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id UUID PRIMARY KEY,
+  transaction_id UUID,
+  account_id UUID NOT NULL,
+  direction text NOT NULL CHECK (direction IN ('debit', 'credit')),
+  amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+  created_at TIMESTAMP NOT NULL,
+  FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+  FOREIGN KEY(account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_id_idx ON ledger_entries (account_id);
+
+-- Balances that predate the ledger get an opening entry without a
+-- transaction, so reconciliation starts from zero drift.
+INSERT INTO ledger_entries (id, transaction_id, account_id, direction, amount, created_at)
+SELECT gen_random_uuid(), NULL, a.id, CASE WHEN a.balance > 0 THEN 'credit' ELSE 'debit' END, ABS(a.balance), NOW()
+  FROM accounts a
+  WHERE a.balance <> 0
+    AND NOT EXISTS (SELECT 1 FROM ledger_entries e WHERE e.account_id = a.id);
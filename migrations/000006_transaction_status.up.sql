@@ -0,0 +1,3 @@
+-- Every transfer stored before statuses existed had been committed.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'completed'
+  CHECK (status IN ('pending', 'completed', 'failed', 'reversed'));
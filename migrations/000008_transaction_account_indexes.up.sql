@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS transactions_from_account_id_created_at_idx ON transactions (from_account_id, created_at);
+CREATE INDEX IF NOT EXISTS transactions_to_account_id_created_at_idx ON transactions (to_account_id, created_at);
@@ -0,0 +1,2 @@
+-- Rows stored before keys existed fall back to the producer's key extractors.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS event_key text;
@@ -0,0 +1,2 @@
+-- Events stored before versioning all match the first version of their schema.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS schema_version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- Fails while customers share an e-mail differing only in case or blanks:
+-- those must be merged by hand first, e.g. found with
+--   SELECT lower(trim(email)), array_agg(id) FROM customers GROUP BY 1 HAVING count(*) > 1;
+UPDATE customers SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_key ON customers (lower(email));
@@ -0,0 +1,6 @@
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE DECIMAL(14, 2);
+ALTER TABLE transactions ALTER COLUMN refunded_amount TYPE DECIMAL(14, 2);
+ALTER TABLE transactions ALTER COLUMN amount TYPE DECIMAL(14, 2);
+ALTER TABLE accounts ALTER COLUMN balance TYPE DECIMAL(12, 2);
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,9 @@
+-- Accounts opened so far were implicitly in reais.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE accounts ALTER COLUMN currency DROP DEFAULT;
+
+-- Room for currencies whose minor unit has more than two decimal places.
+ALTER TABLE accounts ALTER COLUMN balance TYPE DECIMAL(19, 4);
+ALTER TABLE transactions ALTER COLUMN amount TYPE DECIMAL(19, 4);
+ALTER TABLE transactions ALTER COLUMN refunded_amount TYPE DECIMAL(19, 4);
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE DECIMAL(19, 4);
@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_amount DECIMAL(19, 4);
+UPDATE transactions SET destination_amount = amount WHERE destination_amount IS NULL;
+ALTER TABLE transactions ALTER COLUMN destination_amount SET NOT NULL;
+
+-- Only set between accounts in different currencies.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(24, 12) CHECK (fx_rate > 0);
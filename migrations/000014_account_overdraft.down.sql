@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_entries;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,14 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit DECIMAL(19, 4) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+  id UUID PRIMARY KEY,
+  entity VARCHAR(64) NOT NULL,
+  entity_id UUID NOT NULL,
+  action VARCHAR(64) NOT NULL,
+  old_value TEXT NOT NULL,
+  new_value TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_entries_entity_idx ON audit_entries (entity, entity_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE accounts DROP COLUMN IF EXISTS max_daily_count;
+ALTER TABLE accounts DROP COLUMN IF EXISTS max_daily_amount;
+ALTER TABLE accounts DROP COLUMN IF EXISTS max_transfer_amount;
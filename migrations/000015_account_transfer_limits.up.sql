@@ -0,0 +1,5 @@
+-- NULL means the limit doesn't apply. Daily limits are checked against
+-- transactions_from_account_id_created_at_idx.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS max_transfer_amount DECIMAL(19, 4) CHECK (max_transfer_amount >= 0);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS max_daily_amount DECIMAL(19, 4) CHECK (max_daily_amount >= 0);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS max_daily_count INTEGER CHECK (max_daily_count >= 0);
@@ -0,0 +1,2 @@
+-- Why a failed transfer was refused, so support can tell what happened to it.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS failure_reason TEXT;
@@ -0,0 +1,6 @@
+-- What the destination gave back of destination_amount, in its currency.
+-- Reversals debit the original destination, so their amount is that share.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refunded_destination_amount DECIMAL(19, 4) NOT NULL DEFAULT 0;
+UPDATE transactions t SET refunded_destination_amount = (
+    SELECT COALESCE(SUM(r.amount), 0) FROM transactions r WHERE r.reversal_of = t.id AND r.status <> 'failed'
+) WHERE t.reversal_of IS NULL;
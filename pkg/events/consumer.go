@@ -0,0 +1,240 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 5
+
+	// Headers set on messages sent to the dead-letter topic.
+	HeaderDeadLetterError     = "x-dead-letter-error"
+	HeaderDeadLetterAttempts  = "x-dead-letter-attempts"
+	HeaderDeadLetterTopic     = "x-dead-letter-topic"
+	HeaderDeadLetterPartition = "x-dead-letter-partition"
+	HeaderDeadLetterOffset    = "x-dead-letter-offset"
+)
+
+// Message is a record read from, or written to, a broker topic.
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+}
+
+type Partition struct {
+	Topic     string
+	Partition int32
+}
+
+// RebalanceListener is told when the consumer group moves partitions.
+type RebalanceListener interface {
+	Assigned(partitions []Partition)
+	Revoked(partitions []Partition)
+}
+
+// Source reads the messages of a consumer group. Rebalances are reported to
+// the listener from within Poll.
+type Source interface {
+	Poll(ctx context.Context) (*Message, error)
+	// Commit records msg and every message before it on its partition as
+	// processed.
+	Commit(msg *Message) error
+	SetRebalanceListener(listener RebalanceListener)
+	Close() error
+}
+
+// Sink writes raw messages, such as poison messages sent to a dead-letter
+// topic.
+type Sink interface {
+	Publish(msg *Message) error
+}
+
+// PermanentError marks a handler failure retrying can't fix: the message goes
+// to the dead-letter topic right away.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+type handlerFunc func(ctx context.Context, event Event, content json.RawMessage) error
+
+// Registry maps event names to their handlers.
+type Registry struct {
+	handlers map[string]handlerFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]handlerFunc)}
+}
+
+// On registers handler for the events named name, decoding their content into
+// T. Content that doesn't decode is a permanent failure.
+func On[T any](registry *Registry, name string, handler func(ctx context.Context, event Event, content T) error) {
+	registry.handlers[name] = func(ctx context.Context, event Event, raw json.RawMessage) error {
+		var content T
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return Permanent(fmt.Errorf("decode %s content: %w", name, err))
+		}
+		event.Content = content
+		return handler(ctx, event, content)
+	}
+}
+
+// Consumer hands every message of a Source, in any Encoding, to the handler
+// registered for its event name and commits it once handled, dead-lettered or
+// skipped for lack of handler. Delivery is at-least-once: a crash before
+// commit redelivers the message, so handlers must be idempotent.
+type Consumer struct {
+	Source          Source
+	Registry        *Registry
+	DeadLetter      Sink
+	DeadLetterTopic string
+	MaxAttempts     int
+	Backoff         func(attempts int) time.Duration
+	sleep           func(ctx context.Context, delay time.Duration) error
+}
+
+func NewConsumer(source Source, registry *Registry, deadLetter Sink, deadLetterTopic string) *Consumer {
+	if source == nil {
+		panic(errors.New("source must not be null"))
+	}
+	if registry == nil {
+		panic(errors.New("registry must not be null"))
+	}
+	if deadLetter == nil {
+		panic(errors.New("deadLetter must not be null"))
+	}
+	consumer := &Consumer{
+		Source:          source,
+		Registry:        registry,
+		DeadLetter:      deadLetter,
+		DeadLetterTopic: deadLetterTopic,
+		MaxAttempts:     DefaultMaxAttempts,
+		Backoff:         ExponentialBackoff(100*time.Millisecond, 10*time.Second),
+		sleep:           sleep,
+	}
+	source.SetRebalanceListener(consumer)
+	return consumer
+}
+
+// Run consumes until ctx is cancelled or a message can neither be handled nor
+// dead-lettered. The message in flight when it stops is left uncommitted.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		msg, err := c.Source.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err = c.process(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err = c.Source.Commit(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) process(ctx context.Context, msg *Message) error {
+	event, content, err := DecodeMessage(msg)
+	if err != nil {
+		return c.deadLetter(msg, 0, fmt.Errorf("decode envelope: %w", err))
+	}
+
+	handler, ok := c.Registry.handlers[event.Name]
+	if !ok {
+		return nil
+	}
+
+	for attempts := 1; ; attempts++ {
+		err := handler(ctx, event, content)
+		if err == nil {
+			return nil
+		}
+
+		var permanent *PermanentError
+		if errors.As(err, &permanent) || attempts >= c.MaxAttempts {
+			return c.deadLetter(msg, attempts, err)
+		}
+
+		log.Printf("consumer: %s %s attempt %d failed: %v", event.Name, event.EID, attempts, err)
+		if err = c.sleep(ctx, c.Backoff(attempts)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) deadLetter(msg *Message, attempts int, cause error) error {
+	headers := make(map[string]string, len(msg.Headers)+5)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderDeadLetterError] = cause.Error()
+	headers[HeaderDeadLetterAttempts] = strconv.Itoa(attempts)
+	headers[HeaderDeadLetterTopic] = msg.Topic
+	headers[HeaderDeadLetterPartition] = strconv.Itoa(int(msg.Partition))
+	headers[HeaderDeadLetterOffset] = strconv.FormatInt(msg.Offset, 10)
+
+	err := c.DeadLetter.Publish(&Message{
+		Topic:   c.DeadLetterTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("dead-letter %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+	}
+	return nil
+}
+
+// Assigned and Revoked only log: messages are handled and committed one at a
+// time within Poll's goroutine, so nothing is in flight when partitions move.
+func (c *Consumer) Assigned(partitions []Partition) {
+	log.Printf("consumer: assigned %v", partitions)
+}
+
+func (c *Consumer) Revoked(partitions []Partition) {
+	log.Printf("consumer: revoked %v", partitions)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
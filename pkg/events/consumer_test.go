@@ -0,0 +1,207 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const (
+	topic           = "wallet.transactions"
+	deadLetterTopic = "wallet.transactions.dlq"
+	group           = "statements"
+)
+
+type transferred struct {
+	AccountID string  `json:"account_id"`
+	Amount    float64 `json:"amount"`
+}
+
+func TestConsumer_Run_HandleTypedContentAndCommit(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("transaction.created", transferred{AccountID: "a", Amount: 10}))
+
+	var handled []transferred
+	registry := events.NewRegistry()
+	events.On(registry, "transaction.created", func(ctx context.Context, event events.Event, content transferred) error {
+		handled = append(handled, content)
+		return nil
+	})
+
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), registry)
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Equal(t, []transferred{{AccountID: "a", Amount: 10}}, handled)
+	assert.Empty(t, broker.Messages(deadLetterTopic))
+}
+
+func TestConsumer_Run_SkipEventsWithoutHandler(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("customer.created", nil))
+
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), events.NewRegistry())
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Empty(t, broker.Messages(deadLetterTopic))
+}
+
+func TestConsumer_Run_RetryTransientFailure(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("transaction.created", transferred{}))
+
+	attempts := 0
+	registry := events.NewRegistry()
+	events.On(registry, "transaction.created", func(ctx context.Context, event events.Event, content transferred) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	var delays []int
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), registry)
+	consumer.Backoff = func(attempts int) time.Duration {
+		delays = append(delays, attempts)
+		return 0
+	}
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []int{1, 2}, delays)
+	assert.Empty(t, broker.Messages(deadLetterTopic))
+}
+
+func TestConsumer_Run_DeadLetterAfterMaxAttempts(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("transaction.created", transferred{}))
+
+	attempts := 0
+	registry := events.NewRegistry()
+	events.On(registry, "transaction.created", func(ctx context.Context, event events.Event, content transferred) error {
+		attempts++
+		return errors.New("database unavailable")
+	})
+
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), registry)
+	consumer.MaxAttempts = 3
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Equal(t, 3, attempts)
+	deadLetters := broker.Messages(deadLetterTopic)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, broker.Messages(topic)[0].Value, deadLetters[0].Value)
+	assert.Equal(t, "database unavailable", deadLetters[0].Headers[events.HeaderDeadLetterError])
+	assert.Equal(t, "3", deadLetters[0].Headers[events.HeaderDeadLetterAttempts])
+	assert.Equal(t, topic, deadLetters[0].Headers[events.HeaderDeadLetterTopic])
+	assert.Equal(t, "0", deadLetters[0].Headers[events.HeaderDeadLetterOffset])
+}
+
+func TestConsumer_Run_DeadLetterPermanentFailureWithoutRetry(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("transaction.created", "not an object"))
+
+	attempts := 0
+	registry := events.NewRegistry()
+	events.On(registry, "transaction.created", func(ctx context.Context, event events.Event, content transferred) error {
+		attempts++
+		return nil
+	})
+
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), registry)
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Equal(t, 0, attempts)
+	deadLetters := broker.Messages(deadLetterTopic)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, "1", deadLetters[0].Headers[events.HeaderDeadLetterAttempts])
+	assert.Contains(t, deadLetters[0].Headers[events.HeaderDeadLetterError], "decode transaction.created content")
+}
+
+func TestConsumer_Run_DeadLetterMalformedEnvelope(t *testing.T) {
+	broker := memory.NewBroker(1)
+	_ = broker.Publish(&events.Message{Topic: topic, Value: []byte("{")})
+
+	consumer := newConsumer(broker, broker.Subscribe(group, topic), events.NewRegistry())
+	runUntilCommitted(t, consumer, broker, 1)
+
+	deadLetters := broker.Messages(deadLetterTopic)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, "0", deadLetters[0].Headers[events.HeaderDeadLetterAttempts])
+	assert.Contains(t, deadLetters[0].Headers[events.HeaderDeadLetterError], "decode envelope")
+}
+
+func TestConsumer_Run_RedeliverUncommittedMessageAfterRebalance(t *testing.T) {
+	broker := memory.NewBroker(1)
+	publish(t, broker, events.NewEvent("transaction.created", transferred{AccountID: "a"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deliveries := 0
+	registry := events.NewRegistry()
+	events.On(registry, "transaction.created", func(ctx context.Context, event events.Event, content transferred) error {
+		deliveries++
+		if deliveries == 1 {
+			// Stop mid-handling, as a crash or revoked partition would.
+			cancel()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	subscription := broker.Subscribe(group, topic)
+	listener := &rebalanceRecorder{}
+	consumer := newConsumer(broker, subscription, registry)
+	subscription.SetRebalanceListener(listener)
+
+	assert.Nil(t, consumer.Run(ctx))
+	assert.Equal(t, int64(0), broker.Committed(group, events.Partition{Topic: topic}))
+
+	subscription.Rebalance()
+	runUntilCommitted(t, consumer, broker, 1)
+
+	assert.Equal(t, 2, deliveries)
+	assert.Equal(t, []string{"assigned", "revoked", "assigned"}, listener.calls)
+}
+
+type rebalanceRecorder struct {
+	calls []string
+}
+
+func (r *rebalanceRecorder) Assigned(partitions []events.Partition) {
+	r.calls = append(r.calls, "assigned")
+}
+
+func (r *rebalanceRecorder) Revoked(partitions []events.Partition) {
+	r.calls = append(r.calls, "revoked")
+}
+
+func newConsumer(broker *memory.Broker, subscription *memory.Subscription, registry *events.Registry) *events.Consumer {
+	consumer := events.NewConsumer(subscription, registry, broker, deadLetterTopic)
+	consumer.Backoff = func(attempts int) time.Duration { return 0 }
+	return consumer
+}
+
+func publish(t *testing.T, broker *memory.Broker, event *events.Event) {
+	value, err := json.Marshal(event)
+	assert.Nil(t, err)
+	assert.Nil(t, broker.Publish(&events.Message{Topic: topic, Key: []byte(event.Key), Value: value}))
+}
+
+// runUntilCommitted runs consumer until the group committed offset on the
+// first partition of topic.
+func runUntilCommitted(t *testing.T, consumer *events.Consumer, broker *memory.Broker, offset int64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		return broker.Committed(group, events.Partition{Topic: topic}) == offset
+	}, time.Second, time.Millisecond)
+	cancel()
+	assert.Nil(t, <-done)
+}
@@ -0,0 +1,214 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType marks a structured mode CloudEvent.
+	CloudEventsContentType = "application/cloudevents+json"
+	JSONContentType        = "application/json"
+
+	HeaderContentType = "content-type"
+	// cloudEventsHeaderPrefix prefixes the attributes of a binary mode
+	// CloudEvent, per the Kafka protocol binding.
+	cloudEventsHeaderPrefix = "ce_"
+)
+
+// Encoding is how events are written to the broker.
+type Encoding string
+
+const (
+	// NativeEncoding writes the Event envelope itself as JSON.
+	NativeEncoding Encoding = "native"
+	// CloudEventsStructured writes a CloudEvents 1.0 JSON document.
+	CloudEventsStructured Encoding = "cloudevents-structured"
+	// CloudEventsBinary writes the content alone, with the CloudEvents
+	// attributes in ce_ headers.
+	CloudEventsBinary Encoding = "cloudevents-binary"
+)
+
+// Encoder turns an event into the value and headers of a message.
+type Encoder interface {
+	Encode(event Event) (value []byte, headers map[string]string, err error)
+}
+
+type NativeEncoder struct{}
+
+func (NativeEncoder) Encode(event Event) ([]byte, map[string]string, error) {
+	value, err := json.Marshal(event)
+	return value, nil, err
+}
+
+// CloudEvent is the structured mode representation of an Event. SchemaVersion
+// is an extension attribute.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   int             `json:"schemaversion,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// CloudEventsEncoder maps EID to id, Name to type, OccurredAt to time and
+// Content to data.
+type CloudEventsEncoder struct {
+	Binary bool
+	// Source identifies the producing service, e.g. "/wallet-core".
+	Source string
+	// Subjects derive the subject of each event name, typically the ID of
+	// the transaction or account the event is about. Events without one have
+	// no subject.
+	Subjects KeyExtractors
+}
+
+// NewEncoder returns the Encoder of encoding. source and subjects only apply
+// to CloudEvents.
+func NewEncoder(encoding Encoding, source string, subjects KeyExtractors) (Encoder, error) {
+	switch encoding {
+	case NativeEncoding, "":
+		return NativeEncoder{}, nil
+	case CloudEventsStructured:
+		return &CloudEventsEncoder{Source: source, Subjects: subjects}, nil
+	case CloudEventsBinary:
+		return &CloudEventsEncoder{Binary: true, Source: source, Subjects: subjects}, nil
+	}
+	return nil, fmt.Errorf("unknown event encoding %q", encoding)
+}
+
+func (e *CloudEventsEncoder) Encode(event Event) ([]byte, map[string]string, error) {
+	cloudEvent, err := e.ToCloudEvent(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !e.Binary {
+		value, err := json.Marshal(cloudEvent)
+		return value, map[string]string{HeaderContentType: CloudEventsContentType}, err
+	}
+
+	headers := map[string]string{
+		HeaderContentType:                       cloudEvent.DataContentType,
+		cloudEventsHeaderPrefix + "specversion": cloudEvent.SpecVersion,
+		cloudEventsHeaderPrefix + "id":          cloudEvent.ID,
+		cloudEventsHeaderPrefix + "source":      cloudEvent.Source,
+		cloudEventsHeaderPrefix + "type":        cloudEvent.Type,
+		cloudEventsHeaderPrefix + "time":        cloudEvent.Time.Format(time.RFC3339Nano),
+	}
+	if cloudEvent.Subject != "" {
+		headers[cloudEventsHeaderPrefix+"subject"] = cloudEvent.Subject
+	}
+	if cloudEvent.SchemaVersion != 0 {
+		headers[cloudEventsHeaderPrefix+"schemaversion"] = strconv.Itoa(cloudEvent.SchemaVersion)
+	}
+	return cloudEvent.Data, headers, nil
+}
+
+func (e *CloudEventsEncoder) ToCloudEvent(event Event) (*CloudEvent, error) {
+	data, err := json.Marshal(event.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject string
+	if extractor, ok := e.Subjects[event.Name]; ok {
+		if subject, err = extractor(event); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              event.EID.String(),
+		Source:          e.Source,
+		Type:            event.Name,
+		Subject:         subject,
+		Time:            event.OccurredAt.UTC(),
+		DataContentType: JSONContentType,
+		SchemaVersion:   event.SchemaVersion,
+		Data:            data,
+	}, nil
+}
+
+// DecodeMessage reads the event in msg, whichever Encoding wrote it. Its
+// content is left encoded for the caller to decode into the right type.
+func DecodeMessage(msg *Message) (Event, json.RawMessage, error) {
+	if specVersion, ok := msg.Headers[cloudEventsHeaderPrefix+"specversion"]; ok {
+		return decodeBinary(msg, specVersion)
+	}
+
+	if strings.HasPrefix(msg.Headers[HeaderContentType], CloudEventsContentType) {
+		var cloudEvent CloudEvent
+		if err := json.Unmarshal(msg.Value, &cloudEvent); err != nil {
+			return Event{}, nil, err
+		}
+		return fromCloudEvent(msg, &cloudEvent)
+	}
+
+	var envelope struct {
+		Event
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		return Event{}, nil, err
+	}
+	envelope.Event.Key = string(msg.Key)
+	return envelope.Event, envelope.Content, nil
+}
+
+func decodeBinary(msg *Message, specVersion string) (Event, json.RawMessage, error) {
+	cloudEvent := CloudEvent{
+		SpecVersion: specVersion,
+		ID:          msg.Headers[cloudEventsHeaderPrefix+"id"],
+		Source:      msg.Headers[cloudEventsHeaderPrefix+"source"],
+		Type:        msg.Headers[cloudEventsHeaderPrefix+"type"],
+		Subject:     msg.Headers[cloudEventsHeaderPrefix+"subject"],
+		Data:        msg.Value,
+	}
+
+	var err error
+	if raw, ok := msg.Headers[cloudEventsHeaderPrefix+"time"]; ok {
+		if cloudEvent.Time, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			return Event{}, nil, fmt.Errorf("ce_time: %w", err)
+		}
+	}
+	if raw, ok := msg.Headers[cloudEventsHeaderPrefix+"schemaversion"]; ok {
+		if cloudEvent.SchemaVersion, err = strconv.Atoi(raw); err != nil {
+			return Event{}, nil, fmt.Errorf("ce_schemaversion: %w", err)
+		}
+	}
+	return fromCloudEvent(msg, &cloudEvent)
+}
+
+func fromCloudEvent(msg *Message, cloudEvent *CloudEvent) (Event, json.RawMessage, error) {
+	if cloudEvent.SpecVersion != CloudEventsSpecVersion {
+		return Event{}, nil, fmt.Errorf("unsupported CloudEvents specversion %q", cloudEvent.SpecVersion)
+	}
+	EID, err := uuid.Parse(cloudEvent.ID)
+	if err != nil {
+		return Event{}, nil, fmt.Errorf("CloudEvents id: %w", err)
+	}
+
+	schemaVersion := cloudEvent.SchemaVersion
+	if schemaVersion == 0 {
+		schemaVersion = DefaultSchemaVersion
+	}
+	return Event{
+		EID:           EID,
+		OccurredAt:    cloudEvent.Time,
+		Name:          cloudEvent.Type,
+		SchemaVersion: schemaVersion,
+		Key:           string(msg.Key),
+	}, cloudEvent.Data, nil
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type transfer struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+func newTransferEvent() Event {
+	event := NewEvent("wallet.core.transaction.created", transfer{ID: "transaction-id", Amount: 10}).WithKey("account-id")
+	event.SchemaVersion = 2
+	event.OccurredAt = time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	return *event
+}
+
+func newCloudEventsEncoder(binary bool) *CloudEventsEncoder {
+	return &CloudEventsEncoder{
+		Binary:   binary,
+		Source:   "/wallet-core",
+		Subjects: KeyExtractors{"wallet.core.transaction.created": ContentField("id")},
+	}
+}
+
+func TestCloudEventsEncoder_Encode_Structured(t *testing.T) {
+	event := newTransferEvent()
+
+	value, headers, err := newCloudEventsEncoder(false).Encode(event)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{HeaderContentType: CloudEventsContentType}, headers)
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "`+event.EID.String()+`",
+		"source": "/wallet-core",
+		"type": "wallet.core.transaction.created",
+		"subject": "transaction-id",
+		"time": "2026-01-02T03:04:05.000000006Z",
+		"datacontenttype": "application/json",
+		"schemaversion": 2,
+		"data": {"id": "transaction-id", "amount": 10}
+	}`, string(value))
+}
+
+func TestCloudEventsEncoder_Encode_Binary(t *testing.T) {
+	event := newTransferEvent()
+
+	value, headers, err := newCloudEventsEncoder(true).Encode(event)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id": "transaction-id", "amount": 10}`, string(value))
+	assert.Equal(t, map[string]string{
+		"content-type":     "application/json",
+		"ce_specversion":   "1.0",
+		"ce_id":            event.EID.String(),
+		"ce_source":        "/wallet-core",
+		"ce_type":          "wallet.core.transaction.created",
+		"ce_subject":       "transaction-id",
+		"ce_time":          "2026-01-02T03:04:05.000000006Z",
+		"ce_schemaversion": "2",
+	}, headers)
+}
+
+func TestCloudEventsEncoder_Encode_OmitSubjectWithoutExtractor(t *testing.T) {
+	event := *NewEvent("wallet.core.customer.created", nil)
+
+	_, headers, err := newCloudEventsEncoder(true).Encode(event)
+
+	assert.Nil(t, err)
+	assert.NotContains(t, headers, "ce_subject")
+}
+
+func TestCloudEventsEncoder_Encode_FailDueToMissingSubject(t *testing.T) {
+	event := *NewEvent("wallet.core.transaction.created", map[string]string{})
+
+	_, _, err := newCloudEventsEncoder(false).Encode(event)
+
+	assert.ErrorContains(t, err, "has no 'id'")
+}
+
+func TestDecodeMessage_RoundTripEveryEncoding(t *testing.T) {
+	event := newTransferEvent()
+	encoders := map[Encoding]Encoder{
+		NativeEncoding:        NativeEncoder{},
+		CloudEventsStructured: newCloudEventsEncoder(false),
+		CloudEventsBinary:     newCloudEventsEncoder(true),
+	}
+
+	for encoding, encoder := range encoders {
+		t.Run(string(encoding), func(t *testing.T) {
+			value, headers, err := encoder.Encode(event)
+			assert.Nil(t, err)
+
+			decoded, content, err := DecodeMessage(&Message{Key: []byte(event.Key), Value: value, Headers: headers})
+
+			assert.Nil(t, err)
+			assert.Equal(t, event.EID, decoded.EID)
+			assert.Equal(t, event.Name, decoded.Name)
+			assert.Equal(t, event.Key, decoded.Key)
+			assert.Equal(t, event.SchemaVersion, decoded.SchemaVersion)
+			assert.True(t, event.OccurredAt.Equal(decoded.OccurredAt))
+			assert.JSONEq(t, `{"id": "transaction-id", "amount": 10}`, string(content))
+		})
+	}
+}
+
+func TestDecodeMessage_FailDueToUnsupportedSpecVersion(t *testing.T) {
+	_, _, err := DecodeMessage(&Message{Headers: map[string]string{"ce_specversion": "0.3"}})
+
+	assert.ErrorContains(t, err, `unsupported CloudEvents specversion "0.3"`)
+}
+
+func TestNewEncoder(t *testing.T) {
+	encoder, err := NewEncoder(NativeEncoding, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, NativeEncoder{}, encoder)
+
+	encoder, err = NewEncoder(CloudEventsBinary, "/wallet-core", nil)
+	assert.Nil(t, err)
+	assert.True(t, encoder.(*CloudEventsEncoder).Binary)
+
+	_, err = NewEncoder("avro", "", nil)
+	assert.ErrorContains(t, err, `unknown event encoding "avro"`)
+}
+
+func TestNativeEncoder_Encode(t *testing.T) {
+	event := newTransferEvent()
+
+	value, headers, err := NativeEncoder{}.Encode(event)
+
+	assert.Nil(t, err)
+	assert.Nil(t, headers)
+	var decoded map[string]json.RawMessage
+	assert.Nil(t, json.Unmarshal(value, &decoded))
+	assert.JSONEq(t, `2`, string(decoded["schema_version"]))
+	assert.NotContains(t, decoded, "Key")
+}
@@ -7,24 +7,49 @@ import (
 )
 
 type Event struct {
-	EID        uuid.UUID   `json:"eid"`
-	OccurredAt time.Time   `json:"occurred_at"`
-	Name       string      `json:"name"`
-	Content    interface{} `json:"content"`
+	EID        uuid.UUID `json:"eid"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Name       string    `json:"name"`
+	// SchemaVersion is the version of Content's contract, see Payload.
+	SchemaVersion int         `json:"schema_version"`
+	Content       interface{} `json:"content"`
+	// Key routes the event to a partition: events sharing a key are
+	// delivered in order.
+	Key string `json:"-"`
+}
+
+// DefaultSchemaVersion is given to events whose content isn't a Payload.
+const DefaultSchemaVersion = 1
+
+// Payload is event content with an explicit contract: changing its JSON in a
+// way consumers can't read requires a new SchemaVersion.
+type Payload interface {
+	EventName() string
+	SchemaVersion() int
 }
 
 func NewEvent(Name string, Content interface{}) *Event {
+	schemaVersion := DefaultSchemaVersion
+	if payload, ok := Content.(Payload); ok {
+		schemaVersion = payload.SchemaVersion()
+	}
 	return &Event{
-		EID:        uuid.New(),
-		OccurredAt: time.Now(),
-		Name:       Name,
-		Content:    Content,
+		EID:           uuid.New(),
+		OccurredAt:    time.Now(),
+		Name:          Name,
+		SchemaVersion: schemaVersion,
+		Content:       Content,
 	}
 }
 
-type EventPublisherInterface interface {
-	Register(event Event) EventPublisherInterface
-	Publish()
+// NewPayloadEvent creates the event payload is the content of.
+func NewPayloadEvent(payload Payload) *Event {
+	return NewEvent(payload.EventName(), payload)
+}
+
+func (e *Event) WithKey(key string) *Event {
+	e.Key = key
+	return e
 }
 
 type Producer interface {
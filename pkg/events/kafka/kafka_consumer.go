@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	ckafka "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"log"
+	"strings"
+	"time"
+)
+
+// pollInterval bounds how long Poll blocks in librdkafka before checking its
+// context again.
+const pollInterval = 100 * time.Millisecond
+
+// Consumer is an events.Source over a ckafka.Consumer. Offsets are only
+// committed through Commit, never automatically.
+type Consumer struct {
+	ConfigMap *ckafka.ConfigMap
+	Topics    []string
+
+	consumer *ckafka.Consumer
+	listener events.RebalanceListener
+}
+
+func NewKafkaConsumer(configMap *ckafka.ConfigMap, topics ...string) *Consumer {
+	if configMap == nil {
+		panic(errors.New("configMap must not be null"))
+	}
+	if len(topics) == 0 || strings.TrimSpace(topics[0]) == "" {
+		panic(errors.New("topics must not be empty"))
+	}
+	return &Consumer{ConfigMap: configMap, Topics: topics}
+}
+
+func (c *Consumer) SetRebalanceListener(listener events.RebalanceListener) {
+	c.listener = listener
+}
+
+// Poll returns the next message, joining the group on first use.
+func (c *Consumer) Poll(ctx context.Context) (*events.Message, error) {
+	consumer, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		message, err := consumer.ReadMessage(pollInterval)
+		if err != nil {
+			var kafkaErr ckafka.Error
+			if errors.As(err, &kafkaErr) && kafkaErr.IsTimeout() {
+				continue
+			}
+			if errors.As(err, &kafkaErr) && !kafkaErr.IsFatal() {
+				log.Println("kafka consumer:", err)
+				continue
+			}
+			return nil, err
+		}
+		return toMessage(message), nil
+	}
+}
+
+func (c *Consumer) Commit(msg *events.Message) error {
+	consumer, err := c.connect()
+	if err != nil {
+		return err
+	}
+	_, err = consumer.CommitOffsets([]ckafka.TopicPartition{{
+		Topic:     &msg.Topic,
+		Partition: msg.Partition,
+		Offset:    ckafka.Offset(msg.Offset + 1),
+	}})
+	return err
+}
+
+// Close leaves the group, which revokes the assigned partitions first.
+func (c *Consumer) Close() error {
+	if c.consumer == nil {
+		return nil
+	}
+	return c.consumer.Close()
+}
+
+func (c *Consumer) connect() (*ckafka.Consumer, error) {
+	if c.consumer != nil {
+		return c.consumer, nil
+	}
+
+	configMap := ckafka.ConfigMap{}
+	for key, value := range *c.ConfigMap {
+		configMap[key] = value
+	}
+	configMap["enable.auto.commit"] = false
+
+	consumer, err := ckafka.NewConsumer(&configMap)
+	if err != nil {
+		return nil, err
+	}
+	if err = consumer.SubscribeTopics(c.Topics, c.rebalance); err != nil {
+		consumer.Close()
+		return nil, err
+	}
+	c.consumer = consumer
+	return consumer, nil
+}
+
+// rebalance reports partition moves to the listener and leaves the
+// assignment itself to librdkafka's default handling.
+func (c *Consumer) rebalance(_ *ckafka.Consumer, event ckafka.Event) error {
+	if c.listener == nil {
+		return nil
+	}
+	switch event := event.(type) {
+	case ckafka.AssignedPartitions:
+		c.listener.Assigned(toPartitions(event.Partitions))
+	case ckafka.RevokedPartitions:
+		c.listener.Revoked(toPartitions(event.Partitions))
+	}
+	return nil
+}
+
+func toMessage(message *ckafka.Message) *events.Message {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return &events.Message{
+		Topic:     *message.TopicPartition.Topic,
+		Partition: message.TopicPartition.Partition,
+		Offset:    int64(message.TopicPartition.Offset),
+		Key:       message.Key,
+		Value:     message.Value,
+		Headers:   headers,
+	}
+}
+
+func toPartitions(topicPartitions []ckafka.TopicPartition) []events.Partition {
+	partitions := make([]events.Partition, 0, len(topicPartitions))
+	for _, topicPartition := range topicPartitions {
+		partitions = append(partitions, events.Partition{
+			Topic:     *topicPartition.Topic,
+			Partition: topicPartition.Partition,
+		})
+	}
+	return partitions
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	ckafka "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConsumer_PollAndCommit(t *testing.T) {
+	cluster, err := ckafka.NewMockCluster(1)
+	assert.Nil(t, err)
+	defer cluster.Close()
+
+	producer := NewKafkaProducer(&ckafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()}, "wallet.transactions", nil)
+	defer producer.Close(context.Background())
+	err = producer.Publish(&events.Message{
+		Key:     []byte("account"),
+		Value:   []byte(`{"name":"transaction.created"}`),
+		Headers: map[string]string{"trace": "1"},
+	})
+	assert.Nil(t, err)
+
+	consumer := NewKafkaConsumer(&ckafka.ConfigMap{
+		"bootstrap.servers": cluster.BootstrapServers(),
+		"group.id":          "statements",
+		"auto.offset.reset": "earliest",
+	}, "wallet.transactions")
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	msg, err := consumer.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wallet.transactions", msg.Topic)
+	assert.Equal(t, "account", string(msg.Key))
+	assert.Equal(t, "1", msg.Headers["trace"])
+	assert.Nil(t, consumer.Commit(msg))
+
+	topic := "wallet.transactions"
+	committed, err := consumer.consumer.Committed([]ckafka.TopicPartition{{Topic: &topic, Partition: msg.Partition}}, 5000)
+	assert.Nil(t, err)
+	assert.Equal(t, ckafka.Offset(msg.Offset+1), committed[0].Offset)
+}
+
+func TestConsumer_Poll_StopWhenContextIsDone(t *testing.T) {
+	cluster, err := ckafka.NewMockCluster(1)
+	assert.Nil(t, err)
+	defer cluster.Close()
+
+	consumer := NewKafkaConsumer(&ckafka.ConfigMap{
+		"bootstrap.servers": cluster.BootstrapServers(),
+		"group.id":          "statements",
+	}, "wallet.transactions")
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = consumer.Poll(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
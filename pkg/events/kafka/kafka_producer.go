@@ -1,18 +1,45 @@
 package kafka
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/alexandrebrunodias/wallet-core/pkg/events"
 	ckafka "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"log"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
+// DefaultCloseTimeout bounds Close when its context has no deadline.
+const DefaultCloseTimeout = 10 * time.Second
+
+var ErrProducerClosed = errors.New("producer is closed")
+
+// Producer shares one ckafka.Producer between every Send. A background
+// goroutine reads the delivery reports and hands each one to the Send
+// waiting for it.
 type Producer struct {
-	ConfigMap    *ckafka.ConfigMap
-	Topic        *string
-	PartitionKey []byte
+	ConfigMap *ckafka.ConfigMap
+	Topic     *string
+	// Topics routes events by name to a topic other than Topic.
+	Topics map[string]string
+	// PartitionKey is used for events without a key of their own.
+	PartitionKey  []byte
+	KeyExtractors events.KeyExtractors
+	// Encoder writes events as their native JSON envelope when nil.
+	Encoder events.Encoder
+
+	connectOnce sync.Once
+	producer    *ckafka.Producer
+	err         error
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan error
+	closed  bool
 }
 
 func NewKafkaProducer(configMap *ckafka.ConfigMap, topic string, partitionKey []byte) *Producer {
@@ -26,29 +53,196 @@ func NewKafkaProducer(configMap *ckafka.ConfigMap, topic string, partitionKey []
 		ConfigMap:    configMap,
 		Topic:        &topic,
 		PartitionKey: partitionKey,
+		pending:      make(map[uint64]chan error),
 	}
 }
 
+// Send returns once the broker acknowledged the event, or with the reason it
+// could not be delivered.
 func (p *Producer) Send(event events.Event, wg *sync.WaitGroup) error {
 	defer wg.Done()
-	producer, err := ckafka.NewProducer(p.ConfigMap)
+	producer, err := p.connect()
 	if err != nil {
 		return err
 	}
 
-	payload, err := json.Marshal(event)
+	value, headers, err := p.encoder().Encode(event)
 	if err != nil {
 		return err
 	}
 
-	message := &ckafka.Message{
-		TopicPartition: ckafka.TopicPartition{Topic: p.Topic, Partition: ckafka.PartitionAny},
-		Value:          payload,
-		Key:            p.PartitionKey,
+	key, err := p.keyOf(event)
+	if err != nil {
+		return err
 	}
+
+	return p.produce(producer, &ckafka.Message{
+		TopicPartition: ckafka.TopicPartition{Topic: p.topicOf(event), Partition: ckafka.PartitionAny},
+		Value:          value,
+		Key:            key,
+		Headers:        toHeaders(headers),
+	})
+}
+
+// Publish writes a raw message to its own topic, or to the producer's when it
+// has none, and waits for its delivery like Send. It makes the producer an
+// events.Sink, e.g. for a consumer's dead-letter topic.
+func (p *Producer) Publish(msg *events.Message) error {
+	producer, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	topic := p.Topic
+	if msg.Topic != "" {
+		topic = &msg.Topic
+	}
+	return p.produce(producer, &ckafka.Message{
+		TopicPartition: ckafka.TopicPartition{Topic: topic, Partition: ckafka.PartitionAny},
+		Value:          msg.Value,
+		Key:            msg.Key,
+		Headers:        toHeaders(msg.Headers),
+	})
+}
+
+func (p *Producer) produce(producer *ckafka.Producer, message *ckafka.Message) error {
+	ID, delivered, err := p.register()
+	if err != nil {
+		return err
+	}
+
+	message.Opaque = ID
 	err = producer.Produce(message, nil)
 	if err != nil {
+		p.resolve(ID, nil)
 		return err
 	}
+	return <-delivered
+}
+
+// Close waits for messages still queued in the producer until ctx is done,
+// then releases it. Sending after Close fails with ErrProducerClosed.
+func (p *Producer) Close(ctx context.Context) error {
+	p.connectOnce.Do(func() {
+		p.err = ErrProducerClosed
+	})
+
+	p.mu.Lock()
+	alreadyClosed := p.closed
+	p.closed = true
+	p.mu.Unlock()
+	if p.producer == nil || alreadyClosed {
+		return nil
+	}
+
+	timeout := DefaultCloseTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	remaining := p.producer.Flush(int(timeout.Milliseconds()))
+	p.producer.Close()
+
+	if remaining > 0 {
+		return fmt.Errorf("%d messages were still queued when the producer closed", remaining)
+	}
 	return nil
 }
+
+func (p *Producer) topicOf(event events.Event) *string {
+	if topic, ok := p.Topics[event.Name]; ok {
+		return &topic
+	}
+	return p.Topic
+}
+
+func (p *Producer) encoder() events.Encoder {
+	if p.Encoder == nil {
+		return events.NativeEncoder{}
+	}
+	return p.Encoder
+}
+
+func (p *Producer) keyOf(event events.Event) ([]byte, error) {
+	key, err := p.KeyExtractors.KeyOf(event)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return p.PartitionKey, nil
+	}
+	return []byte(key), nil
+}
+
+// connect creates the underlying producer on first use.
+func (p *Producer) connect() (*ckafka.Producer, error) {
+	p.connectOnce.Do(func() {
+		p.producer, p.err = ckafka.NewProducer(p.ConfigMap)
+		if p.err == nil {
+			go p.dispatchDeliveryReports(p.producer.Events())
+		}
+	})
+	return p.producer, p.err
+}
+
+func (p *Producer) register() (uint64, chan error, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, nil, ErrProducerClosed
+	}
+	p.nextID++
+	delivered := make(chan error, 1)
+	p.pending[p.nextID] = delivered
+	return p.nextID, delivered, nil
+}
+
+func (p *Producer) resolve(ID uint64, err error) {
+	p.mu.Lock()
+	delivered, ok := p.pending[ID]
+	delete(p.pending, ID)
+	p.mu.Unlock()
+	if ok {
+		delivered <- err
+	}
+}
+
+func (p *Producer) dispatchDeliveryReports(reports chan ckafka.Event) {
+	for report := range reports {
+		switch report := report.(type) {
+		case *ckafka.Message:
+			if ID, ok := report.Opaque.(uint64); ok {
+				p.resolve(ID, report.TopicPartition.Error)
+			}
+		case ckafka.Error:
+			log.Println("kafka producer:", report)
+		}
+	}
+
+	// The channel closes with the producer: whatever is left will never be
+	// reported.
+	p.mu.Lock()
+	pending := p.pending
+	p.pending = make(map[uint64]chan error)
+	p.mu.Unlock()
+	for _, delivered := range pending {
+		delivered <- ErrProducerClosed
+	}
+}
+
+// toHeaders sorts headers by name so messages are written deterministically.
+func toHeaders(headers map[string]string) []ckafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	kafkaHeaders := make([]ckafka.Header, 0, len(headers))
+	for _, name := range names {
+		kafkaHeaders = append(kafkaHeaders, ckafka.Header{Key: name, Value: []byte(headers[name])})
+	}
+	return kafkaHeaders
+}
@@ -0,0 +1,118 @@
+package kafka
+
+import (
+	"context"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	ckafka "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProducer_Send_WaitForDeliveryReport(t *testing.T) {
+	producer := NewKafkaProducer(&ckafka.ConfigMap{
+		"test.mock.num.brokers": 1,
+		"enable.idempotence":    true,
+	}, "wallet.transactions", nil)
+	defer producer.Close(context.Background())
+
+	for i := 0; i < 3; i++ {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		err := producer.Send(*events.NewEvent("event", i), wg)
+		wg.Wait()
+
+		assert.Nil(t, err)
+	}
+
+	assert.Empty(t, producer.pending)
+}
+
+func TestProducer_Send_FailDueToDeliveryTimeout(t *testing.T) {
+	producer := NewKafkaProducer(&ckafka.ConfigMap{
+		"bootstrap.servers":  "127.0.0.1:1",
+		"message.timeout.ms": 100,
+	}, "wallet.transactions", nil)
+	defer producer.Close(context.Background())
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	err := producer.Send(*events.NewEvent("event", nil), wg)
+	wg.Wait()
+
+	var kafkaErr ckafka.Error
+	assert.ErrorAs(t, err, &kafkaErr)
+	assert.Equal(t, ckafka.ErrMsgTimedOut, kafkaErr.Code())
+}
+
+func TestProducer_Send_FailAfterClose(t *testing.T) {
+	producer := NewKafkaProducer(&ckafka.ConfigMap{"test.mock.num.brokers": 1}, "wallet.transactions", nil)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	_ = producer.Send(*events.NewEvent("event", nil), wg)
+
+	err := producer.Close(context.Background())
+	assert.Nil(t, err)
+
+	wg.Add(1)
+	err = producer.Send(*events.NewEvent("event", nil), wg)
+
+	assert.ErrorIs(t, err, ErrProducerClosed)
+}
+
+func TestProducer_KeyOf_FallBackToPartitionKey(t *testing.T) {
+	producer := NewKafkaProducer(&ckafka.ConfigMap{}, "wallet.transactions", []byte("default"))
+	producer.KeyExtractors = events.KeyExtractors{"created": events.ContentField("from_account_id")}
+
+	key, err := producer.keyOf(*events.NewEvent("created", map[string]string{"from_account_id": "from"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("from"), key)
+
+	key, err = producer.keyOf(*events.NewEvent("other", nil).WithKey("own"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("own"), key)
+
+	key, err = producer.keyOf(*events.NewEvent("other", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("default"), key)
+}
+
+func TestProducer_Send_WriteCloudEventsHeaders(t *testing.T) {
+	cluster, err := ckafka.NewMockCluster(1)
+	assert.Nil(t, err)
+	defer cluster.Close()
+
+	producer := NewKafkaProducer(&ckafka.ConfigMap{"bootstrap.servers": cluster.BootstrapServers()}, "wallet.transactions", nil)
+	producer.Encoder = &events.CloudEventsEncoder{Binary: true, Source: "/wallet-core"}
+	defer producer.Close(context.Background())
+
+	event := events.NewEvent("wallet.core.transaction.created", map[string]string{"id": "transaction-id"})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	assert.Nil(t, producer.Send(*event, wg))
+
+	consumer := NewKafkaConsumer(&ckafka.ConfigMap{
+		"bootstrap.servers": cluster.BootstrapServers(),
+		"group.id":          "statements",
+		"auto.offset.reset": "earliest",
+	}, "wallet.transactions")
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	msg, err := consumer.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, event.EID.String(), msg.Headers["ce_id"])
+	assert.Equal(t, "/wallet-core", msg.Headers["ce_source"])
+	assert.JSONEq(t, `{"id": "transaction-id"}`, string(msg.Value))
+}
+
+func TestProducer_TopicOf_RouteByEventName(t *testing.T) {
+	producer := NewKafkaProducer(&ckafka.ConfigMap{}, "wallet.transactions", nil)
+	producer.Topics = map[string]string{"wallet.core.account.balance_updated": "wallet.balances"}
+
+	assert.Equal(t, "wallet.balances", *producer.topicOf(*events.NewEvent("wallet.core.account.balance_updated", nil)))
+	assert.Equal(t, "wallet.transactions", *producer.topicOf(*events.NewEvent("wallet.core.transaction.created", nil)))
+}
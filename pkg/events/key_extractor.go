@@ -0,0 +1,51 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// KeyExtractor derives the key of an event created without one.
+type KeyExtractor func(event Event) (string, error)
+
+// KeyExtractors holds the KeyExtractor of each event name.
+type KeyExtractors map[string]KeyExtractor
+
+// KeyOf returns the key the event carries, or the one its extractor derives.
+// Events without either have no key.
+func (k KeyExtractors) KeyOf(event Event) (string, error) {
+	if event.Key != "" {
+		return event.Key, nil
+	}
+	extractor, ok := k[event.Name]
+	if !ok {
+		return "", nil
+	}
+	return extractor(event)
+}
+
+// ContentField reads the key from a top-level field of the JSON encoded
+// content. It works both on the original content and on the json.RawMessage
+// read back from the outbox.
+func ContentField(field string) KeyExtractor {
+	return func(event Event) (string, error) {
+		content, err := json.Marshal(event.Content)
+		if err != nil {
+			return "", err
+		}
+
+		var fields map[string]interface{}
+		if err = json.Unmarshal(content, &fields); err != nil {
+			return "", fmt.Errorf("%s content is not an object: %w", event.Name, err)
+		}
+
+		value, ok := fields[field]
+		if !ok || value == nil {
+			return "", fmt.Errorf("%s content has no '%s'", event.Name, field)
+		}
+		if key, ok := value.(string); ok {
+			return key, nil
+		}
+		return fmt.Sprint(value), nil
+	}
+}
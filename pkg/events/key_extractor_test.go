@@ -0,0 +1,51 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKeyExtractors_KeyOf_PreferEventKey(t *testing.T) {
+	extractors := KeyExtractors{"created": ContentField("from_account_id")}
+	event := NewEvent("created", map[string]string{"from_account_id": "from"}).WithKey("own")
+
+	key, err := extractors.KeyOf(*event)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "own", key)
+}
+
+func TestKeyExtractors_KeyOf_ExtractFromContent(t *testing.T) {
+	type content struct {
+		FromAccountID string `json:"from_account_id"`
+	}
+	extractors := KeyExtractors{"created": ContentField("from_account_id")}
+
+	key, err := extractors.KeyOf(*NewEvent("created", content{FromAccountID: "from"}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "from", key)
+
+	key, err = extractors.KeyOf(*NewEvent("created", json.RawMessage(`{"from_account_id":"raw"}`)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "raw", key)
+}
+
+func TestKeyExtractors_KeyOf_NoKeyWithoutExtractor(t *testing.T) {
+	key, err := KeyExtractors{}.KeyOf(*NewEvent("unknown", nil))
+
+	assert.Nil(t, err)
+	assert.Empty(t, key)
+}
+
+func TestKeyExtractors_KeyOf_FailDueToMissingField(t *testing.T) {
+	extractors := KeyExtractors{"created": ContentField("from_account_id")}
+
+	key, err := extractors.KeyOf(*NewEvent("created", map[string]string{"id": "123"}))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "created content has no 'from_account_id'", err.Error())
+	assert.Empty(t, key)
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+)
+
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// Broker is an in-process stand-in for Kafka: partitioned topics, keyed
+// routing and committed offsets per consumer group.
+type Broker struct {
+	partitions int
+	mu         sync.Mutex
+	topics     map[string][][]*events.Message
+	committed  map[string]map[events.Partition]int64
+	published  chan struct{}
+}
+
+func NewBroker(partitions int) *Broker {
+	if partitions < 1 {
+		partitions = 1
+	}
+	return &Broker{
+		partitions: partitions,
+		topics:     make(map[string][][]*events.Message),
+		committed:  make(map[string]map[events.Partition]int64),
+		published:  make(chan struct{}),
+	}
+}
+
+// Publish appends msg to its topic, on the partition its key hashes to.
+func (b *Broker) Publish(msg *events.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions := b.topic(msg.Topic)
+	partition := b.partitionOf(msg.Key)
+	stored := *msg
+	stored.Partition = partition
+	stored.Offset = int64(len(partitions[partition]))
+	partitions[partition] = append(partitions[partition], &stored)
+
+	close(b.published)
+	b.published = make(chan struct{})
+	return nil
+}
+
+// Messages returns every message published to topic, partition by partition.
+func (b *Broker) Messages(topic string) []*events.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []*events.Message
+	for _, partition := range b.topics[topic] {
+		messages = append(messages, partition...)
+	}
+	return messages
+}
+
+// Committed returns the next offset group will read from partition.
+func (b *Broker) Committed(group string, partition events.Partition) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[group][partition]
+}
+
+// Subscribe joins group on topics. Each subscription owns every partition of
+// its topics and starts from the group's committed offsets.
+func (b *Broker) Subscribe(group string, topics ...string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var partitions []events.Partition
+	for _, topic := range topics {
+		b.topic(topic)
+		for i := 0; i < b.partitions; i++ {
+			partitions = append(partitions, events.Partition{Topic: topic, Partition: int32(i)})
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Topic != partitions[j].Topic {
+			return partitions[i].Topic < partitions[j].Topic
+		}
+		return partitions[i].Partition < partitions[j].Partition
+	})
+	return &Subscription{broker: b, group: group, partitions: partitions, rebalance: true}
+}
+
+func (b *Broker) topic(name string) [][]*events.Message {
+	partitions, ok := b.topics[name]
+	if !ok {
+		partitions = make([][]*events.Message, b.partitions)
+		b.topics[name] = partitions
+	}
+	return partitions
+}
+
+func (b *Broker) partitionOf(key []byte) int32 {
+	hash := fnv.New32a()
+	_, _ = hash.Write(key)
+	return int32(hash.Sum32() % uint32(b.partitions))
+}
+
+// Subscription reads a consumer group's share of the broker. It isn't safe
+// for concurrent use, like a Kafka consumer.
+type Subscription struct {
+	broker     *Broker
+	group      string
+	partitions []events.Partition
+	position   map[events.Partition]int64
+	next       int
+	listener   events.RebalanceListener
+	rebalance  bool
+	closed     bool
+}
+
+func (s *Subscription) SetRebalanceListener(listener events.RebalanceListener) {
+	s.listener = listener
+}
+
+// Rebalance revokes and reassigns the subscription's partitions on the next
+// Poll, rewinding to the committed offsets as a new group member would.
+func (s *Subscription) Rebalance() {
+	s.rebalance = true
+}
+
+func (s *Subscription) Poll(ctx context.Context) (*events.Message, error) {
+	for {
+		if s.closed {
+			return nil, ErrSubscriptionClosed
+		}
+		if s.rebalance {
+			s.assign()
+		}
+
+		msg, published := s.take()
+		if msg != nil {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-published:
+		}
+	}
+}
+
+func (s *Subscription) Commit(msg *events.Message) error {
+	if s.closed {
+		return ErrSubscriptionClosed
+	}
+
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	committed, ok := s.broker.committed[s.group]
+	if !ok {
+		committed = make(map[events.Partition]int64)
+		s.broker.committed[s.group] = committed
+	}
+	partition := events.Partition{Topic: msg.Topic, Partition: msg.Partition}
+	if msg.Offset+1 > committed[partition] {
+		committed[partition] = msg.Offset + 1
+	}
+	return nil
+}
+
+func (s *Subscription) Close() error {
+	if !s.closed && s.listener != nil && s.position != nil {
+		s.listener.Revoked(s.partitions)
+	}
+	s.closed = true
+	return nil
+}
+
+func (s *Subscription) assign() {
+	if s.listener != nil && s.position != nil {
+		s.listener.Revoked(s.partitions)
+	}
+
+	s.broker.mu.Lock()
+	s.position = make(map[events.Partition]int64, len(s.partitions))
+	for _, partition := range s.partitions {
+		s.position[partition] = s.broker.committed[s.group][partition]
+	}
+	s.broker.mu.Unlock()
+
+	s.rebalance = false
+	if s.listener != nil {
+		s.listener.Assigned(s.partitions)
+	}
+}
+
+// take returns the next unread message, visiting partitions round-robin, or
+// the channel closed by the next Publish when there is none.
+func (s *Subscription) take() (*events.Message, chan struct{}) {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	for i := 0; i < len(s.partitions); i++ {
+		partition := s.partitions[(s.next+i)%len(s.partitions)]
+		messages := s.broker.topics[partition.Topic][partition.Partition]
+		position := s.position[partition]
+		if position < int64(len(messages)) {
+			s.position[partition] = position + 1
+			s.next = (s.next + i + 1) % len(s.partitions)
+			msg := *messages[position]
+			return &msg, nil
+		}
+	}
+	return nil, s.broker.published
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBroker_Publish_KeepOrderPerKey(t *testing.T) {
+	broker := NewBroker(4)
+	for _, value := range []string{"1", "2", "3"} {
+		_ = broker.Publish(&events.Message{Topic: "topic", Key: []byte("account"), Value: []byte(value)})
+	}
+
+	messages := broker.Messages("topic")
+
+	assert.Len(t, messages, 3)
+	for i, msg := range messages {
+		assert.Equal(t, messages[0].Partition, msg.Partition)
+		assert.Equal(t, int64(i), msg.Offset)
+	}
+}
+
+func TestSubscription_Poll_ResumeFromCommittedOffset(t *testing.T) {
+	broker := NewBroker(1)
+	for _, value := range []string{"1", "2"} {
+		_ = broker.Publish(&events.Message{Topic: "topic", Value: []byte(value)})
+	}
+
+	first := broker.Subscribe("group", "topic")
+	msg, err := first.Poll(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, first.Commit(msg))
+	assert.Nil(t, first.Close())
+
+	second := broker.Subscribe("group", "topic")
+	msg, err = second.Poll(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", string(msg.Value))
+	assert.Equal(t, int64(1), broker.Committed("group", events.Partition{Topic: "topic"}))
+}
+
+func TestSubscription_Poll_WaitForPublish(t *testing.T) {
+	broker := NewBroker(1)
+	subscription := broker.Subscribe("group", "topic")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = broker.Publish(&events.Message{Topic: "topic", Value: []byte("late")})
+	}()
+	msg, err := subscription.Poll(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "late", string(msg.Value))
+}
+
+func TestSubscription_Poll_FailWhenContextIsDone(t *testing.T) {
+	subscription := NewBroker(1).Subscribe("group", "topic")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := subscription.Poll(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// OutboxMessage is an event persisted in the same database transaction as
+// the state change that produced it, waiting to be handed to a Producer.
+type OutboxMessage struct {
+	ID            uuid.UUID
+	Event         Event
+	Attempts      int
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
+
+type OutboxStore interface {
+	// FetchPending claims up to limit messages due at now, in creation order,
+	// so no other relay fetches them until they are marked or the claim runs
+	// out. It leaves out messages queued behind an undelivered one sharing
+	// their key.
+	FetchPending(now time.Time, limit int) ([]*OutboxMessage, error)
+	MarkDelivered(ID uuid.UUID, deliveredAt time.Time) error
+	MarkFailed(ID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error
+}
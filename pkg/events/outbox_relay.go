@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultOutboxBatchSize    = 100
+	DefaultOutboxPollInterval = time.Second
+)
+
+// OutboxRelay polls an OutboxStore and hands pending messages to a Producer,
+// marking them delivered on success and rescheduling them with backoff on
+// failure. Delivery is at-least-once: consumers must tolerate duplicates.
+//
+// Several relays may run against the same store, as FetchPending claims the
+// messages it returns. Messages sharing a key are relayed in creation order:
+// a failed one holds back the ones behind it until it is delivered.
+type OutboxRelay struct {
+	Store        OutboxStore
+	Producer     Producer
+	BatchSize    int
+	PollInterval time.Duration
+	Backoff      func(attempts int) time.Duration
+	now          func() time.Time
+}
+
+func NewOutboxRelay(store OutboxStore, producer Producer) *OutboxRelay {
+	if store == nil {
+		panic(errors.New("store must not be null"))
+	}
+	if producer == nil {
+		panic(errors.New("producer must not be null"))
+	}
+	return &OutboxRelay{
+		Store:        store,
+		Producer:     producer,
+		BatchSize:    DefaultOutboxBatchSize,
+		PollInterval: DefaultOutboxPollInterval,
+		Backoff:      ExponentialBackoff(time.Second, 5*time.Minute),
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run relays pending messages until ctx is cancelled. A batch holds at most
+// one message per key, so as long as batches deliver something the next one
+// is fetched straight away; PollInterval only paces an idle or failing relay.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := r.RelayPending()
+		if err != nil {
+			log.Println("outbox relay:", err)
+		}
+		if err == nil && delivered > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending sends one batch of pending messages in creation order and
+// returns how many of them were delivered. A failed message is rescheduled
+// and the rest of the batch is still sent, as the store returns at most one
+// message per key; should a later one share the failed message's key, it is
+// skipped and comes back once its claim expires, behind the failed one.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	messages, err := r.Store.FetchPending(r.now(), r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	failedKeys := map[string]bool{}
+	for _, message := range messages {
+		if message.Event.Key != "" && failedKeys[message.Event.Key] {
+			continue
+		}
+
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		err = r.Producer.Send(message.Event, wg)
+		wg.Wait()
+
+		if err != nil {
+			attempts := message.Attempts + 1
+			nextAttemptAt := r.now().Add(r.Backoff(attempts))
+			if errMark := r.Store.MarkFailed(message.ID, attempts, nextAttemptAt, err.Error()); errMark != nil {
+				return delivered, errMark
+			}
+			failedKeys[message.Event.Key] = true
+			continue
+		}
+
+		if err = r.Store.MarkDelivered(message.ID, r.now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// ExponentialBackoff doubles the delay on every attempt, starting at base and
+// never exceeding max.
+func ExponentialBackoff(base time.Duration, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		if attempts < 1 {
+			return base
+		}
+		delay := float64(base) * math.Pow(2, float64(attempts-1))
+		if delay > float64(max) {
+			return max
+		}
+		return time.Duration(delay)
+	}
+}
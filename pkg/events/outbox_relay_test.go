@@ -0,0 +1,174 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutboxRelay_RelayPending_MarkDeliveredSuccessfully(t *testing.T) {
+	now := time.Now().UTC()
+	message := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("any.event", nil)}
+
+	storeMock := &OutboxStoreMock{}
+	storeMock.On("FetchPending", now, DefaultOutboxBatchSize).Return([]*OutboxMessage{message}, nil)
+	storeMock.On("MarkDelivered", message.ID, now).Return(nil)
+
+	producerMock := &ProducerMock{}
+	producerMock.On("Send", message.Event).Return(nil)
+
+	relay := NewOutboxRelay(storeMock, producerMock)
+	relay.now = func() time.Time { return now }
+	delivered, err := relay.RelayPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+
+	storeMock.AssertExpectations(t)
+	storeMock.AssertNotCalled(t, "MarkFailed")
+	producerMock.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestOutboxRelay_RelayPending_RescheduleWithBackoffOnFailure(t *testing.T) {
+	now := time.Now().UTC()
+	expectedErrorMessage := "broker unavailable"
+	message := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("any.event", nil), Attempts: 2}
+
+	storeMock := &OutboxStoreMock{}
+	storeMock.On("FetchPending", now, DefaultOutboxBatchSize).Return([]*OutboxMessage{message}, nil)
+	storeMock.On("MarkFailed", message.ID, 3, now.Add(4*time.Second), expectedErrorMessage).Return(nil)
+
+	producerMock := &ProducerMock{}
+	producerMock.On("Send", message.Event).Return(errors.New(expectedErrorMessage))
+
+	relay := NewOutboxRelay(storeMock, producerMock)
+	relay.now = func() time.Time { return now }
+	delivered, err := relay.RelayPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+
+	storeMock.AssertExpectations(t)
+	storeMock.AssertNotCalled(t, "MarkDelivered")
+}
+
+func TestOutboxRelay_RelayPending_KeepSendingOtherKeysAfterFailure(t *testing.T) {
+	now := time.Now().UTC()
+	failed := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("any.event", nil).WithKey("account")}
+	other := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("other.event", nil).WithKey("other-account")}
+
+	storeMock := &OutboxStoreMock{}
+	storeMock.On("FetchPending", now, DefaultOutboxBatchSize).Return([]*OutboxMessage{failed, other}, nil)
+	storeMock.On("MarkFailed", failed.ID, 1, now.Add(time.Second), "broker unavailable").Return(nil)
+	storeMock.On("MarkDelivered", other.ID, now).Return(nil)
+
+	producerMock := &ProducerMock{}
+	producerMock.On("Send", failed.Event).Return(errors.New("broker unavailable"))
+	producerMock.On("Send", other.Event).Return(nil)
+
+	relay := NewOutboxRelay(storeMock, producerMock)
+	relay.now = func() time.Time { return now }
+	delivered, err := relay.RelayPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+
+	storeMock.AssertExpectations(t)
+	producerMock.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestOutboxRelay_RelayPending_FailDueToStoreError(t *testing.T) {
+	expectedErrorMessage := "store error"
+
+	storeMock := &OutboxStoreMock{}
+	storeMock.On("FetchPending", m.Anything, m.Anything).Return([]*OutboxMessage{}, errors.New(expectedErrorMessage))
+
+	producerMock := &ProducerMock{}
+
+	relay := NewOutboxRelay(storeMock, producerMock)
+	delivered, err := relay.RelayPending()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedErrorMessage, err.Error())
+	assert.Equal(t, 0, delivered)
+
+	producerMock.AssertNotCalled(t, "Send")
+}
+
+func TestOutboxRelay_Run_FetchAgainStraightAwayWhileDelivering(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	message := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("any.event", nil).WithKey("account")}
+	next := &OutboxMessage{ID: uuid.New(), Event: *NewEvent("other.event", nil).WithKey("account")}
+
+	storeMock := &OutboxStoreMock{}
+	storeMock.On("FetchPending", m.Anything, DefaultOutboxBatchSize).Return([]*OutboxMessage{message}, nil).Once()
+	storeMock.On("FetchPending", m.Anything, DefaultOutboxBatchSize).Return([]*OutboxMessage{next}, nil).Once()
+	storeMock.On("FetchPending", m.Anything, DefaultOutboxBatchSize).
+		Run(func(m.Arguments) { cancel() }).
+		Return([]*OutboxMessage{}, nil).Once()
+	storeMock.On("MarkDelivered", m.Anything, m.Anything).Return(nil)
+
+	producerMock := &ProducerMock{}
+	producerMock.On("Send", m.Anything).Return(nil)
+
+	relay := NewOutboxRelay(storeMock, producerMock)
+	relay.PollInterval = time.Hour
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay waited for PollInterval between batches that delivered messages")
+	}
+	storeMock.AssertNumberOfCalls(t, "FetchPending", 3)
+	producerMock.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestExponentialBackoff_CappedAtMax(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 10*time.Second)
+
+	assert.Equal(t, time.Second, backoff(1))
+	assert.Equal(t, 2*time.Second, backoff(2))
+	assert.Equal(t, 8*time.Second, backoff(4))
+	assert.Equal(t, 10*time.Second, backoff(5))
+}
+
+type OutboxStoreMock struct {
+	m.Mock
+}
+
+func (s *OutboxStoreMock) FetchPending(now time.Time, limit int) ([]*OutboxMessage, error) {
+	args := s.Called(now, limit)
+	return args.Get(0).([]*OutboxMessage), args.Error(1)
+}
+
+func (s *OutboxStoreMock) MarkDelivered(ID uuid.UUID, deliveredAt time.Time) error {
+	args := s.Called(ID, deliveredAt)
+	return args.Error(0)
+}
+
+func (s *OutboxStoreMock) MarkFailed(ID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	args := s.Called(ID, attempts, nextAttemptAt, lastError)
+	return args.Error(0)
+}
+
+type ProducerMock struct {
+	m.Mock
+}
+
+func (p *ProducerMock) Send(event Event, wg *sync.WaitGroup) error {
+	defer wg.Done()
+	args := p.Called(event)
+	return args.Error(0)
+}
@@ -0,0 +1,273 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema event payloads are described with.
+type Schema struct {
+	Schema        string             `json:"$schema,omitempty"`
+	ID            string             `json:"$id,omitempty"`
+	Title         string             `json:"title,omitempty"`
+	Type          Types              `json:"type,omitempty"`
+	Format        string             `json:"format,omitempty"`
+	Pattern       string             `json:"pattern,omitempty"`
+	Properties    map[string]*Schema `json:"properties,omitempty"`
+	Required      []string           `json:"required,omitempty"`
+	Items         *Schema            `json:"items,omitempty"`
+	Additional    *Schema            `json:"additionalProperties,omitempty"`
+	SchemaVersion int                `json:"x-schema-version,omitempty"`
+}
+
+// Types is a schema's "type": a single name, or several when e.g. null is
+// allowed too.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(content []byte) error {
+	var name string
+	if err := json.Unmarshal(content, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	return json.Unmarshal(content, (*[]string)(t))
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	decimalType   = reflect.TypeOf(decimal.Decimal{})
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate describes the JSON encoding/json produces for v.
+func Generate(v interface{}) *Schema {
+	schema := generate(reflect.TypeOf(v))
+	schema.Schema = Draft
+	return schema
+}
+
+func generate(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		nullable = true
+		t = t.Elem()
+	}
+
+	schema := describe(t)
+	if nullable {
+		schema.Type = append(schema.Type, "null")
+	}
+	return schema
+}
+
+func describe(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: Types{"string"}, Format: "uuid"}
+	case decimalType:
+		return &Schema{Type: Types{"string"}, Pattern: `^-?[0-9]+(\.[0-9]+)?$`}
+	}
+	if t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{"array", "null"}, Items: generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, Additional: generate(t.Elem())}
+	case reflect.Struct:
+		return describeStruct(t)
+	}
+	// Interfaces and the like accept anything.
+	return &Schema{}
+}
+
+func describeStruct(t reflect.Type) *Schema {
+	schema := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		// Untagged embedded structs are flattened, as encoding/json does.
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			flattened := describe(embedded)
+			for property, propertySchema := range flattened.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, flattened.Required...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = generate(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// ForPayload describes payload's content, identified by its event name and
+// schema version.
+func ForPayload(payload events.Payload) *Schema {
+	schema := Generate(payload)
+	schema.ID = FileName(payload)
+	schema.Title = payload.EventName()
+	schema.SchemaVersion = payload.SchemaVersion()
+	return schema
+}
+
+// FileName is the name payload's schema is stored under.
+func FileName(payload events.Payload) string {
+	return fmt.Sprintf("%s.v%d.json", payload.EventName(), payload.SchemaVersion())
+}
+
+// Compatible reports every change in current that breaks a consumer written
+// against previous: removed or retyped properties, required properties
+// becoming optional and properties becoming required, which messages written
+// against previous lack. Adding optional properties is always compatible.
+func Compatible(previous, current *Schema) error {
+	return compatible("", previous, current)
+}
+
+func compatible(path string, previous, current *Schema) error {
+	var errs []error
+	if !sameStrings(previous.Type, current.Type) {
+		errs = append(errs, fmt.Errorf("%s: type changed from %v to %v", describePath(path), previous.Type, current.Type))
+	}
+	if previous.Format != current.Format {
+		errs = append(errs, fmt.Errorf("%s: format changed from %q to %q", describePath(path), previous.Format, current.Format))
+	}
+	if previous.Pattern != current.Pattern {
+		errs = append(errs, fmt.Errorf("%s: pattern changed from %q to %q", describePath(path), previous.Pattern, current.Pattern))
+	}
+
+	names := make([]string, 0, len(previous.Properties))
+	for name := range previous.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := current.Properties[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: property removed", describePath(path+"."+name)))
+			continue
+		}
+		if err := compatible(path+"."+name, previous.Properties[name], property); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range previous.Required {
+		if !contains(current.Required, name) {
+			errs = append(errs, fmt.Errorf("%s: property is no longer required", describePath(path+"."+name)))
+		}
+	}
+	for _, name := range current.Required {
+		if !contains(previous.Required, name) {
+			errs = append(errs, fmt.Errorf("%s: property is newly required", describePath(path+"."+name)))
+		}
+	}
+
+	if previous.Items != nil && current.Items != nil {
+		if err := compatible(path+"[]", previous.Items, current.Items); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if previous.Additional != nil && current.Additional != nil {
+		if err := compatible(path+"{}", previous.Additional, current.Additional); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Marshal encodes schema the way schema files are written.
+func Marshal(schema *Schema) ([]byte, error) {
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func Unmarshal(content []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "payload"
+	}
+	return strings.TrimPrefix(path, ".")
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
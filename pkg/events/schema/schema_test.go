@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type line struct {
+	Description string `json:"description"`
+}
+
+type payloadV1 struct {
+	ID         uuid.UUID       `json:"id"`
+	Amount     decimal.Decimal `json:"amount"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Note       *string         `json:"note,omitempty"`
+	Lines      []line          `json:"lines"`
+	Internal   string          `json:"-"`
+}
+
+func TestGenerate(t *testing.T) {
+	schema := Generate(payloadV1{})
+
+	assert.Equal(t, Draft, schema.Schema)
+	assert.Equal(t, Types{"object"}, schema.Type)
+	assert.Equal(t, []string{"amount", "id", "lines", "occurred_at"}, schema.Required)
+	assert.Equal(t, "uuid", schema.Properties["id"].Format)
+	assert.Equal(t, Types{"string"}, schema.Properties["amount"].Type)
+	assert.Equal(t, "date-time", schema.Properties["occurred_at"].Format)
+	assert.Equal(t, Types{"string", "null"}, schema.Properties["note"].Type)
+	assert.Equal(t, Types{"array", "null"}, schema.Properties["lines"].Type)
+	assert.Equal(t, []string{"description"}, schema.Properties["lines"].Items.Required)
+	assert.NotContains(t, schema.Properties, "Internal")
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	schema := Generate(payloadV1{})
+
+	content, err := Marshal(schema)
+	assert.Nil(t, err)
+	decoded, err := Unmarshal(content)
+
+	assert.Nil(t, err)
+	assert.Equal(t, schema, decoded)
+}
+
+func TestCompatible_AllowAddedOptionalProperties(t *testing.T) {
+	type payloadWithNewField struct {
+		payloadV1
+		Currency string `json:"currency,omitempty"`
+	}
+
+	assert.Nil(t, Compatible(Generate(payloadV1{}), Generate(payloadWithNewField{})))
+}
+
+func TestCompatible_RejectNewlyRequiredProperty(t *testing.T) {
+	type payloadWithNewField struct {
+		payloadV1
+		Currency string `json:"currency"`
+	}
+
+	err := Compatible(Generate(payloadV1{}), Generate(payloadWithNewField{}))
+
+	assert.ErrorContains(t, err, "currency: property is newly required")
+}
+
+func TestCompatible_RejectRenamedProperty(t *testing.T) {
+	type renamed struct {
+		ID         uuid.UUID       `json:"id"`
+		Value      decimal.Decimal `json:"value"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Lines      []line          `json:"lines"`
+	}
+
+	err := Compatible(Generate(payloadV1{}), Generate(renamed{}))
+
+	assert.ErrorContains(t, err, "amount: property removed")
+}
+
+func TestCompatible_RejectChangedType(t *testing.T) {
+	type retyped struct {
+		ID         string    `json:"id"`
+		Amount     float64   `json:"amount"`
+		OccurredAt time.Time `json:"occurred_at"`
+		Lines      []line    `json:"lines"`
+	}
+
+	err := Compatible(Generate(payloadV1{}), Generate(retyped{}))
+
+	assert.ErrorContains(t, err, "id: format changed")
+	assert.ErrorContains(t, err, "amount: type changed from [string] to [number]")
+}
+
+func TestCompatible_RejectRequiredPropertyBecomingOptional(t *testing.T) {
+	type optional struct {
+		ID         uuid.UUID       `json:"id"`
+		Amount     decimal.Decimal `json:"amount,omitempty"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Lines      []struct {
+			Description int `json:"description"`
+		} `json:"lines"`
+	}
+
+	err := Compatible(Generate(payloadV1{}), Generate(optional{}))
+
+	assert.ErrorContains(t, err, "amount: property is no longer required")
+	assert.ErrorContains(t, err, "lines[].description: type changed")
+}
@@ -0,0 +1,145 @@
+// Package wallet holds the payloads of the events wallet-core publishes.
+//
+// A payload type is a published contract: its JSON Schema is kept under
+// schemas/ and TestSchemas fails on changes consumers can't read. Such
+// changes need a new type with the next SchemaVersion, e.g.
+// TransactionCreatedV2, published alongside or instead of the old one.
+//
+//go:generate go run ../../../cmd/eventschema -dir schemas
+package wallet
+
+import (
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	TransactionCreated    = "wallet.core.transaction.created"
+	TransactionReversed   = "wallet.core.transaction.reversed"
+	AccountBalanceUpdated = "wallet.core.account.balance_updated"
+	AccountLimitChanged   = "wallet.core.account.limit_changed"
+	CustomerUpdated       = "wallet.core.customer.updated"
+)
+
+// Payloads lists the latest version of every payload, the ones schemas are
+// generated for.
+var Payloads = []events.Payload{
+	TransactionCreatedV1{},
+	TransactionReversedV1{},
+	AccountBalanceUpdatedV1{},
+	AccountLimitChangedV1{},
+	CustomerUpdatedV1{},
+}
+
+// TransactionCreatedV1 carries Amount in the currency of the debited account
+// and DestinationAmount in the one of the credited account. FXRate is only
+// set when they differ. Messages from before currencies existed lack Currency,
+// DestinationAmount and DestinationCurrency, and moved Amount as is.
+type TransactionCreatedV1 struct {
+	ID                  uuid.UUID        `json:"id"`
+	FromAccountID       uuid.UUID        `json:"from_account_id"`
+	ToAccountID         uuid.UUID        `json:"to_account_id"`
+	Amount              decimal.Decimal  `json:"amount"`
+	Currency            string           `json:"currency,omitempty"`
+	DestinationAmount   *decimal.Decimal `json:"destination_amount,omitempty"`
+	DestinationCurrency string           `json:"destination_currency,omitempty"`
+	FXRate              *decimal.Decimal `json:"fx_rate,omitempty"`
+	Status              string           `json:"status"`
+}
+
+func (TransactionCreatedV1) EventName() string {
+	return TransactionCreated
+}
+
+func (TransactionCreatedV1) SchemaVersion() int {
+	return 1
+}
+
+// TransactionReversedV1 uses the currencies of the reversal's own accounts,
+// so Amount is in the currency of the original destination. Like in
+// TransactionCreatedV1, older messages lack the currencies and
+// DestinationAmount.
+type TransactionReversedV1 struct {
+	ID                      uuid.UUID        `json:"id"`
+	OriginalTransactionID   uuid.UUID        `json:"original_transaction_id"`
+	FromAccountID           uuid.UUID        `json:"from_account_id"`
+	ToAccountID             uuid.UUID        `json:"to_account_id"`
+	Amount                  decimal.Decimal  `json:"amount"`
+	Currency                string           `json:"currency,omitempty"`
+	DestinationAmount       *decimal.Decimal `json:"destination_amount,omitempty"`
+	DestinationCurrency     string           `json:"destination_currency,omitempty"`
+	FXRate                  *decimal.Decimal `json:"fx_rate,omitempty"`
+	Status                  string           `json:"status"`
+	OriginalStatus          string           `json:"original_status"`
+	OriginalRemainingAmount decimal.Decimal  `json:"original_remaining_amount"`
+}
+
+func (TransactionReversedV1) EventName() string {
+	return TransactionReversed
+}
+
+func (TransactionReversedV1) SchemaVersion() int {
+	return 1
+}
+
+// AccountBalanceUpdatedV1 carries the balances a transaction left both of its
+// accounts with. Versions grow with every balance change, so a projection can
+// ignore updates older than the one it holds. Older messages lack the
+// currencies.
+type AccountBalanceUpdatedV1 struct {
+	TransactionID       uuid.UUID       `json:"transaction_id"`
+	FromAccountID       uuid.UUID       `json:"from_account_id"`
+	FromAccountBalance  decimal.Decimal `json:"from_account_balance"`
+	FromAccountCurrency string          `json:"from_account_currency,omitempty"`
+	FromAccountVersion  int64           `json:"from_account_version"`
+	ToAccountID         uuid.UUID       `json:"to_account_id"`
+	ToAccountBalance    decimal.Decimal `json:"to_account_balance"`
+	ToAccountCurrency   string          `json:"to_account_currency,omitempty"`
+	ToAccountVersion    int64           `json:"to_account_version"`
+}
+
+func (AccountBalanceUpdatedV1) EventName() string {
+	return AccountBalanceUpdated
+}
+
+func (AccountBalanceUpdatedV1) SchemaVersion() int {
+	return 1
+}
+
+// AccountLimitChangedV1 carries both the overdraft limit an account had and
+// the one it was given, in the currency of the account. Version is the one the
+// change left the account with, shared with AccountBalanceUpdatedV1.
+type AccountLimitChangedV1 struct {
+	AccountID              uuid.UUID       `json:"account_id"`
+	Currency               string          `json:"currency"`
+	PreviousOverdraftLimit decimal.Decimal `json:"previous_overdraft_limit"`
+	OverdraftLimit         decimal.Decimal `json:"overdraft_limit"`
+	Reason                 string          `json:"reason"`
+	Version                int64           `json:"version"`
+	ChangedAt              time.Time       `json:"changed_at"`
+}
+
+func (AccountLimitChangedV1) EventName() string {
+	return AccountLimitChanged
+}
+
+func (AccountLimitChangedV1) SchemaVersion() int {
+	return 1
+}
+
+type CustomerUpdatedV1 struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CustomerUpdatedV1) EventName() string {
+	return CustomerUpdated
+}
+
+func (CustomerUpdatedV1) SchemaVersion() int {
+	return 1
+}
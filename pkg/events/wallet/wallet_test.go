@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"encoding/json"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events"
+	"github.com/alexandrebrunodias/wallet-core/pkg/events/schema"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSchemas fails when a payload stops matching the schema consumers were
+// given. Compatible changes only need `go generate ./pkg/events/wallet`;
+// incompatible ones need a new schema version.
+func TestSchemas(t *testing.T) {
+	for _, payload := range Payloads {
+		t.Run(schema.FileName(payload), func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("schemas", schema.FileName(payload)))
+			if !assert.Nil(t, err, "schema missing, run go generate ./pkg/events/wallet") {
+				return
+			}
+			published, err := schema.Unmarshal(content)
+			assert.Nil(t, err)
+
+			current := schema.ForPayload(payload)
+
+			assert.Nil(t, schema.Compatible(published, current), "backward-incompatible change, publish a new schema version instead")
+			assert.Equal(t, published, current, "schema out of date, run go generate ./pkg/events/wallet")
+		})
+	}
+}
+
+func TestPayloads_UniqueNameAndVersion(t *testing.T) {
+	seen := map[string]bool{}
+	for _, payload := range Payloads {
+		assert.False(t, seen[schema.FileName(payload)], schema.FileName(payload))
+		seen[schema.FileName(payload)] = true
+		assert.Equal(t, payload.SchemaVersion(), events.NewPayloadEvent(payload).SchemaVersion)
+	}
+}
+
+// TestPayloads_OmitEmptyOptionalProperties fails when an optional property is
+// encoded although it was never set, e.g. a struct tagged omitempty, which
+// encoding/json never considers empty.
+func TestPayloads_OmitEmptyOptionalProperties(t *testing.T) {
+	for _, payload := range Payloads {
+		t.Run(schema.FileName(payload), func(t *testing.T) {
+			content, err := json.Marshal(payload)
+			assert.Nil(t, err)
+			var encoded map[string]json.RawMessage
+			assert.Nil(t, json.Unmarshal(content, &encoded))
+
+			names := make([]string, 0, len(encoded))
+			for name := range encoded {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, schema.ForPayload(payload).Required, names)
+		})
+	}
+}
@@ -13,7 +13,7 @@ type UnitOfWorkInterface interface {
 	Add(name string, repository Repository)
 	Remove(name string)
 	GetRepository(ctx context.Context, name string) (interface{}, error)
-	Do(ctx context.Context, fn func(unitOfWork *UnitOfWork) error) error
+	Do(ctx context.Context, fn func(unitOfWork UnitOfWorkInterface) error) error
 	CommitOrRollback() error
 	RollBack() error
 }
@@ -53,20 +53,27 @@ func (u *UnitOfWork) GetRepository(ctx context.Context, name string) (interface{
 	return u.repositories[name](u.Tx), nil
 }
 
-func (u *UnitOfWork) Do(ctx context.Context, fn func(unitOfWork *UnitOfWork) error) error {
+// Do runs fn inside its own database transaction. fn receives a unit of work
+// scoped to that transaction, so concurrent calls sharing u never interleave.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(unitOfWork UnitOfWorkInterface) error) error {
 	if u.Tx != nil {
 		return errors.New("transaction is already started")
 	}
 
+	scoped := &UnitOfWork{
+		Db:           u.Db,
+		repositories: u.repositories,
+	}
+
 	var err error
-	u.Tx, err = u.Db.BeginTx(ctx, nil)
+	scoped.Tx, err = u.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	err = fn(u)
+	err = fn(scoped)
 	if err != nil {
-		errRollBack := u.RollBack()
+		errRollBack := scoped.RollBack()
 		if errRollBack != nil {
 			return errors.New(fmt.Sprintf(
 				"transaction error: %s | rollback error: %s",
@@ -76,7 +83,7 @@ func (u *UnitOfWork) Do(ctx context.Context, fn func(unitOfWork *UnitOfWork) err
 		}
 		return err
 	}
-	return u.CommitOrRollback()
+	return scoped.CommitOrRollback()
 }
 
 func (u *UnitOfWork) CommitOrRollback() error {